@@ -0,0 +1,132 @@
+package statigz
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// AccessPolicy controls which files can be served, denied files are reported as not found.
+//
+// Patterns are path.Match globs matched against file name relative to served root, for example "private/*",
+// pattern without "/" is matched against base name, for example "*.bak". Pattern matching a directory
+// applies to all files in that directory.
+type AccessPolicy struct {
+	// AllowDotfiles enables serving of files and directories with names starting with ".",
+	// they are denied by default.
+	AllowDotfiles bool
+
+	// Deny lists patterns of denied files.
+	Deny []string
+
+	// Allow lists patterns of files that are allowed regardless of Deny, dotfiles and source maps,
+	// for example ".well-known" or "*.map".
+	Allow []string
+
+	// SourceMaps is a check of access to source maps ("*.map"), for example HeaderToken("X-Source-Map-Token", "secret").
+	// Source maps are denied if SourceMaps is nil, unless they are allowed with Allow.
+	SourceMaps func(r *http.Request) bool
+}
+
+// Access is an option to set file access policy.
+func Access(policy AccessPolicy) func(server *Server) {
+	return func(server *Server) {
+		server.Access = policy
+	}
+}
+
+// HeaderToken returns a check that request header has expected value, it can be used with AccessPolicy.SourceMaps.
+func HeaderToken(header, token string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		return subtle.ConstantTimeCompare([]byte(r.Header.Get(header)), []byte(token)) == 1
+	}
+}
+
+// matchPatternOrDir checks if file name or any of its parent directories matches glob.
+func matchPatternOrDir(pattern, name string) bool {
+	for name != "." && name != "/" && name != "" {
+		if matchPattern(pattern, name) {
+			return true
+		}
+
+		name = path.Dir(name)
+	}
+
+	return false
+}
+
+// allowed checks if file is allowed with Allow patterns.
+func (p AccessPolicy) allowed(name string) bool {
+	for _, pattern := range p.Allow {
+		if matchPatternOrDir(pattern, strings.Trim(name, "/")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sourceMap checks if file is a source map that is not allowed with Allow patterns.
+func (p AccessPolicy) sourceMap(name string) bool {
+	return strings.HasSuffix(name, ".map") && !p.allowed(name)
+}
+
+// hidden checks if file is denied regardless of request.
+func (p AccessPolicy) hidden(name string) bool {
+	name = strings.Trim(name, "/")
+
+	if p.allowed(name) {
+		return false
+	}
+
+	if !p.AllowDotfiles && (strings.HasPrefix(name, ".") || strings.Contains(name, "/.")) {
+		return true
+	}
+
+	for _, pattern := range p.Deny {
+		if matchPatternOrDir(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// denied checks if file with name relative to file system is not accessible for request.
+//
+// Encoded files, for example "app.js.map.gz", are checked by their names without encoding extensions.
+func (s *Server) denied(req *http.Request, fn string) bool {
+	return s.anyName(fn, func(name string) bool {
+		if s.Access.hidden(name) {
+			return true
+		}
+
+		return s.Access.sourceMap(name) && (s.Access.SourceMaps == nil || !s.Access.SourceMaps(req))
+	})
+}
+
+// listed checks if file with name relative to file system can be exposed in Manifest and listings.
+func (s *Server) listed(fn string) bool {
+	return !s.anyName(fn, func(name string) bool {
+		return s.Access.hidden(name) || s.Access.sourceMap(name)
+	})
+}
+
+// anyName checks name relative to file system and names without encoding extensions.
+func (s *Server) anyName(fn string, check func(name string) bool) bool {
+	name := strings.TrimPrefix(fn, s.fsPrefix)
+
+	for {
+		if check(name) {
+			return true
+		}
+
+		base := s.encodedBase(name)
+		if base == name {
+			return false
+		}
+
+		name = base
+	}
+}
@@ -0,0 +1,216 @@
+package statigz_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+	"github.com/vearutop/statigz/brotli"
+)
+
+func TestAccess(t *testing.T) {
+	fsys := fstest.MapFS{
+		".env":                     {Data: []byte("SECRET=1")},
+		".git/config":              {Data: []byte("[core]")},
+		".well-known/security.txt": {Data: []byte("Contact: a@example.com")},
+		"app.js":                   {Data: []byte("app")},
+		"app.js.map":               {Data: []byte("{}")},
+		"config.bak":               {Data: []byte("backup")},
+		"private/key.txt":          {Data: []byte("key")},
+		"public/.hidden.txt":       {Data: []byte("hidden")},
+	}
+
+	s := statigz.FileServer(fsys, statigz.DirListing(), statigz.Access(statigz.AccessPolicy{
+		Deny:       []string{"private", "*.bak"},
+		Allow:      []string{".well-known"},
+		SourceMaps: statigz.HeaderToken("X-Source-Map-Token", "secret"),
+	}))
+
+	for u, found := range map[string]bool{
+		"/.env":                     false,
+		"/.git/config":              false,
+		"/.git":                     false,
+		"/.git/":                    false,
+		"/.well-known/security.txt": true,
+		"/app.js":                   true,
+		"/app.js.map":               false,
+		"/config.bak":               false,
+		"/private/key.txt":          false,
+		"/private":                  false,
+		"/public/.hidden.txt":       false,
+	} {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		assert.Equal(t, found, s.Found(req), u)
+
+		if found {
+			assert.Equal(t, http.StatusOK, rw.Code, u)
+		} else {
+			assert.Equal(t, http.StatusNotFound, rw.Code, u)
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/app.js.map", nil)
+	require.NoError(t, err)
+
+	req.Header.Set("X-Source-Map-Token", "secret")
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.True(t, s.Found(req))
+
+	assert.Equal(t, map[string]string{
+		".well-known/security.txt": "/.well-known/security.txt",
+		"app.js":                   "/app.js",
+	}, s.Manifest())
+
+	req, err = http.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, err)
+
+	req.Header.Set("Accept", "application/json")
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.JSONEq(t, `{"path":"/","entries":[
+		{"name":".well-known","isDir":true},
+		{"name":"public","isDir":true},
+		{"name":"app.js","size":3}
+	]}`, rw.Body.String())
+}
+
+func TestAccess_defaultDotfiles(t *testing.T) {
+	s := statigz.FileServer(fstest.MapFS{".env": {Data: []byte("SECRET=1")}})
+
+	req, err := http.NewRequest(http.MethodGet, "/.env", nil)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	s = statigz.FileServer(fstest.MapFS{".env": {Data: []byte("SECRET=1")}},
+		statigz.Access(statigz.AccessPolicy{AllowDotfiles: true}))
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+}
+
+func TestAccess_defaultSourceMaps(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js":     {Data: []byte("app")},
+		"app.js.map": {Data: []byte("{}")},
+	}
+
+	for _, tc := range []struct {
+		policy statigz.AccessPolicy
+		found  bool
+	}{
+		{policy: statigz.AccessPolicy{}, found: false},
+		{policy: statigz.AccessPolicy{Allow: []string{"*.map"}}, found: true},
+		{policy: statigz.AccessPolicy{SourceMaps: func(r *http.Request) bool { return true }}, found: true},
+	} {
+		s := statigz.FileServer(fsys, statigz.Access(tc.policy))
+
+		req, err := http.NewRequest(http.MethodGet, "/app.js.map", nil)
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		assert.Equal(t, tc.found, s.Found(req))
+
+		if tc.found {
+			assert.Equal(t, http.StatusOK, rw.Code)
+		} else {
+			assert.Equal(t, http.StatusNotFound, rw.Code)
+		}
+	}
+
+	// Source maps are denied by default.
+	s := statigz.FileServer(fsys)
+
+	req, err := http.NewRequest(http.MethodGet, "/app.js.map", nil)
+	require.NoError(t, err)
+
+	assert.False(t, s.Found(req))
+	assert.NotContains(t, s.Manifest(), "app.js.map")
+}
+
+func TestAccess_encoded(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js":          {Data: []byte("app")},
+		"app.js.map.gz":   {Data: gzipped(t, "{}")},
+		"app.js.map.br":   {Data: []byte("brotli")},
+		"secret.bak.gz":   {Data: gzipped(t, "secret")},
+		".env.gz":         {Data: gzipped(t, "SECRET=1")},
+		"public.txt.gz":   {Data: gzipped(t, "public")},
+		"assets/a.css":    {Data: []byte("a")},
+		"assets/a.css.gz": {Data: gzipped(t, "a")},
+	}
+
+	s := statigz.FileServer(fsys, brotli.AddEncoding, statigz.Access(statigz.AccessPolicy{
+		Deny:       []string{"*.bak"},
+		SourceMaps: statigz.HeaderToken("X-Source-Map-Token", "secret"),
+	}))
+
+	for u, found := range map[string]bool{
+		"/app.js":          true,
+		"/app.js.map":      false,
+		"/app.js.map.gz":   false,
+		"/app.js.map.br":   false,
+		"/secret.bak":      false,
+		"/secret.bak.gz":   false,
+		"/.env":            false,
+		"/.env.gz":         false,
+		"/public.txt":      true,
+		"/public.txt.gz":   true,
+		"/assets/a.css.gz": true,
+	} {
+		for _, ae := range []string{"", "gzip, br"} {
+			req, err := http.NewRequest(http.MethodGet, u, nil)
+			require.NoError(t, err)
+
+			req.Header.Set("Accept-Encoding", ae)
+
+			rw := httptest.NewRecorder()
+			s.ServeHTTP(rw, req)
+
+			assert.Equal(t, found, s.Found(req), u)
+
+			if found {
+				assert.Equal(t, http.StatusOK, rw.Code, u)
+			} else {
+				assert.Equal(t, http.StatusNotFound, rw.Code, u)
+			}
+		}
+	}
+
+	m := s.Manifest()
+	assert.NotContains(t, m, "app.js.map")
+	assert.NotContains(t, m, "secret.bak")
+
+	// Source maps are available with token.
+	req, err := http.NewRequest(http.MethodGet, "/app.js.map.gz", nil)
+	require.NoError(t, err)
+
+	req.Header.Set("X-Source-Map-Token", "secret")
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+}
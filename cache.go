@@ -0,0 +1,160 @@
+package statigz
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// CacheRule maps files to Cache-Control header value.
+//
+// File name is relative to served root and has no leading "/", for example "assets/app.js".
+// Rule is applied if any of Pattern, Regexp or Match is matching.
+type CacheRule struct {
+	// Pattern is a path.Match glob, for example "assets/*.js",
+	// pattern without "/" is matched against base name of a file, for example "*.png".
+	Pattern string
+
+	// Regexp is matched against file name.
+	Regexp *regexp.Regexp
+
+	// Match is a custom matcher of file name.
+	Match func(name string) bool
+
+	// Value is a Cache-Control header value, empty value disables the header.
+	Value string
+}
+
+func (r CacheRule) matches(name string) bool {
+	if r.Pattern != "" && matchPattern(r.Pattern, name) {
+		return true
+	}
+
+	if r.Regexp != nil && r.Regexp.MatchString(name) {
+		return true
+	}
+
+	return r.Match != nil && r.Match(name)
+}
+
+// matchPattern checks if file name matches glob, pattern without "/" is matched against base name.
+func matchPattern(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		name = path.Base(name)
+	}
+
+	matched, err := path.Match(pattern, name)
+
+	return err == nil && matched
+}
+
+// DefaultCacheRules returns rules for immutable caching of files with hashed names
+// and revalidation of HTML documents.
+func DefaultCacheRules() []CacheRule {
+	return []CacheRule{
+		{Match: IsHashedName, Value: immutableCacheControl},
+		{Pattern: "*.html", Value: "no-cache"},
+		{Pattern: "*.htm", Value: "no-cache"},
+	}
+}
+
+// IsHashedName checks if file name contains content hash, as produced by bundlers
+// like Vite ("index-B-x4aQz1.js") or webpack ("main.7e2c5c8d1f.js"), or by Fingerprint.
+//
+// Hash is expected to be a hex string of at least 8 characters with digits and letters,
+// or an 8 characters base64url string with digits and letters that has mixed-case letters or interleaves
+// letters and digits, so that dates ("sales-20240101.csv"), versions ("app-version2.js") and words
+// ("Inter-SemiBold.woff2") are not mistaken for hashes.
+func IsHashedName(name string) bool {
+	base := path.Base(name)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+
+	if stem == base || stem == "" {
+		return false
+	}
+
+	if i := strings.LastIndexAny(stem, ".-"); i != -1 && isHexHash(stem[i+1:]) {
+		return true
+	}
+
+	if len(stem) <= fingerprintLen {
+		return false
+	}
+
+	sep := stem[len(stem)-fingerprintLen-1]
+
+	return (sep == '.' || sep == '-') && isShortHash(stem[len(stem)-fingerprintLen:])
+}
+
+// isHexHash checks if string is a hex hash of at least 8 characters with digits and letters.
+func isHexHash(h string) bool {
+	if len(h) < fingerprintLen {
+		return false
+	}
+
+	var digit, letter bool
+
+	for _, r := range h {
+		switch {
+		case r >= '0' && r <= '9':
+			digit = true
+		case r >= 'a' && r <= 'f':
+			letter = true
+		default:
+			return false
+		}
+	}
+
+	return digit && letter
+}
+
+// isShortHash checks if string is a base64url hash that has digits and letters,
+// and either has mixed-case letters or alternates letters and digits at least twice.
+func isShortHash(h string) bool {
+	var (
+		upper, lower, digit bool
+		switches            int
+		prev                rune
+	)
+
+	for _, r := range h {
+		var class rune
+
+		switch {
+		case unicode.IsDigit(r):
+			digit = true
+			class = 'd'
+		case unicode.IsUpper(r):
+			upper = true
+			class = 'l'
+		case unicode.IsLower(r):
+			lower = true
+			class = 'l'
+		case r == '-' || r == '_':
+			continue
+		default:
+			return false
+		}
+
+		if prev != 0 && prev != class {
+			switches++
+		}
+
+		prev = class
+	}
+
+	// Words, for example "SemiBold", are not hashes.
+	return digit && (upper || lower) && (upper && lower || switches >= 2)
+}
+
+// cacheControl returns Cache-Control value of the first matching rule.
+func (s *Server) cacheControl(name string) string {
+	for _, r := range s.CacheRules {
+		if r.matches(name) {
+			return r.Value
+		}
+	}
+
+	return ""
+}
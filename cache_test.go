@@ -0,0 +1,101 @@
+package statigz_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+func TestIsHashedName(t *testing.T) {
+	for name, hashed := range map[string]bool{
+		"assets/index-BxT4aQz1.js":      true,
+		"assets/index-DlBxLmsB.js":      false, // No digits, could be a word.
+		"main.7e2c5c8d1f.js":            true,
+		"swagger.1bp69hxb.json":         true,
+		"index-B-x4aQz1.js":             true,
+		"assets/main-index-B_x4aqz1.js": true,
+		"sales-20240101.csv":            false,
+		"report-2024-01-01.csv":         false,
+		"app-version2.js":               false,
+		"2024Q1-summary.pdf":            false,
+		"deadbeef.js":                   false,
+		"Inter-SemiBold.woff2":          false,
+		"user-Settings.js":              false,
+		"app.js":                        false,
+		"jquery-3.6.0.min.js":           false,
+		"bootstrap-material.css":        false,
+		"my-Component.js":               false,
+		"vendor.chunk.js":               false,
+		"fonts/roboto-regular.woff":     false,
+	} {
+		assert.Equal(t, hashed, statigz.IsHashedName(name), name)
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	s := statigz.FileServer(v, statigz.FSPrefix("testdata"), statigz.Fingerprint, statigz.CacheControl(
+		statigz.CacheRule{Pattern: "*.png", Value: "public, max-age=3600"},
+		statigz.CacheRule{Regexp: regexp.MustCompile(`^deeper/`), Value: "public, max-age=60"},
+	))
+
+	u, err := s.URL("swagger.json")
+	require.NoError(t, err)
+
+	for p, cc := range map[string]string{
+		"/favicon.png":         "public, max-age=3600",
+		"/deeper/openapi.json": "public, max-age=60",
+		"/":                    "no-cache",
+		"/swagger.json":        "",
+		u:                      "public, max-age=31536000, immutable",
+	} {
+		req, err := http.NewRequest(http.MethodGet, p, nil)
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		assert.Equal(t, http.StatusOK, rw.Code, p)
+		assert.Equal(t, cc, rw.Header().Get("Cache-Control"), p)
+
+		// Rules are also applied to Not Modified responses.
+		req.Header.Set("If-None-Match", rw.Header().Get("Etag"))
+
+		rw = httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		assert.Equal(t, http.StatusNotModified, rw.Code, p)
+		assert.Equal(t, cc, rw.Header().Get("Cache-Control"), p)
+	}
+}
+
+func TestCacheControl_failed(t *testing.T) {
+	s := statigz.FileServer(fstest.MapFS{
+		"assets/index-B-x4aQz1.js.gz": {Data: []byte("not a gzip")},
+		"assets/index-C4xYz9Qa.js":    {Data: []byte("ok")},
+	}, statigz.CacheControl())
+
+	for p, code := range map[string]int{
+		"/assets/index-C4xYz9Qa.js": http.StatusOK,
+		"/assets/index-B-x4aQz1.js": http.StatusInternalServerError,
+	} {
+		req, err := http.NewRequest(http.MethodGet, p, nil)
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		assert.Equal(t, code, rw.Code, p)
+
+		if code == http.StatusOK {
+			assert.Equal(t, "public, max-age=31536000, immutable", rw.Header().Get("Cache-Control"), p)
+		} else {
+			assert.Equal(t, "", rw.Header().Get("Cache-Control"), p)
+		}
+	}
+}
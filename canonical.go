@@ -0,0 +1,57 @@
+package statigz
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// canonicalPath returns cleaned URL path, it returns false for invalid paths and paths that escape root.
+func canonicalPath(p string) (string, bool) {
+	if strings.ContainsRune(p, 0) {
+		return "", false
+	}
+
+	depth := 0
+
+	for _, seg := range strings.Split(p, "/") {
+		switch seg {
+		case "", ".":
+		case "..":
+			depth--
+
+			if depth < 0 {
+				return "", false
+			}
+		default:
+			depth++
+		}
+	}
+
+	c := path.Clean("/" + p)
+
+	if strings.HasSuffix(p, "/") && c != "/" {
+		c += "/"
+	}
+
+	return c, true
+}
+
+// redirectCanonical rejects invalid paths and redirects non-canonical paths,
+// like "//a.js", "/b/../a.js" or "/./a.js", to canonical form, it returns true if response is complete.
+func (s *Server) redirectCanonical(rw http.ResponseWriter, req *http.Request) bool {
+	c, ok := canonicalPath(req.URL.Path)
+	if !ok {
+		http.Error(rw, "Bad Request\n\ninvalid URL path", http.StatusBadRequest)
+
+		return true
+	}
+
+	if c == req.URL.Path {
+		return false
+	}
+
+	s.redirectAbsolute(rw, req, c, http.StatusMovedPermanently)
+
+	return true
+}
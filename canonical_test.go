@@ -0,0 +1,130 @@
+package statigz_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+// get follows redirects and returns final response.
+func get(t *testing.T, h http.Handler, u string) (*httptest.ResponseRecorder, []string) {
+	t.Helper()
+
+	var redirects []string
+
+	for i := 0; i < 5; i++ {
+		req, err := http.NewRequest(http.MethodGet, "http://localhost", nil)
+		require.NoError(t, err)
+
+		req.URL.Path = u
+
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, req)
+
+		if rw.Code != http.StatusMovedPermanently {
+			return rw, redirects
+		}
+
+		loc, err := url.Parse(rw.Header().Get("Location"))
+		require.NoError(t, err)
+
+		u = req.URL.ResolveReference(loc).Path
+		redirects = append(redirects, rw.Header().Get("Location"))
+	}
+
+	t.Fatal("too many redirects")
+
+	return nil, nil
+}
+
+func TestServer_ServeHTTP_canonical(t *testing.T) {
+	sub, err := fs.Sub(v, "testdata")
+	require.NoError(t, err)
+
+	s := statigz.FileServer(sub)
+	std := http.FileServer(http.FS(sub))
+
+	for _, tc := range []struct {
+		path      string
+		redirects []string
+		stdCode   int
+		code      int
+	}{
+		{path: "/swagger.json", code: http.StatusOK},
+		{path: "//swagger.json", redirects: []string{"/swagger.json"}, code: http.StatusOK},
+		{path: "/./swagger.json", redirects: []string{"/swagger.json"}, code: http.StatusOK},
+		{path: "/deeper/../swagger.json", redirects: []string{"/swagger.json"}, code: http.StatusOK},
+		{path: "/deeper/./openapi.json.gz", redirects: []string{"/deeper/openapi.json.gz"}, code: http.StatusOK},
+		{path: "/deeper//swagger.json.br", redirects: []string{"/deeper/swagger.json.br"}, code: http.StatusOK},
+		{path: "/nonexistent/../favicon.png", redirects: []string{"/favicon.png"}, code: http.StatusOK},
+		{path: "/deeper/.././", redirects: []string{"/"}, code: http.StatusOK},
+		{path: "/nonexistent/../missing.png", redirects: []string{"/missing.png"}, code: http.StatusNotFound},
+		{path: "/../swagger.json", stdCode: http.StatusOK, code: http.StatusBadRequest},
+		{path: "/deeper/../../swagger.json", stdCode: http.StatusOK, code: http.StatusBadRequest},
+		{path: "swagger.json", code: http.StatusOK},
+		{path: "", code: http.StatusOK},
+		{path: "/swagger.json\x00", stdCode: http.StatusNotFound, code: http.StatusBadRequest},
+	} {
+		rw, redirects := get(t, s, tc.path)
+		stdRw, _ := get(t, std, tc.path)
+
+		assert.Equal(t, tc.code, rw.Code, tc.path)
+		assert.Equal(t, tc.redirects, redirects, tc.path)
+
+		if tc.stdCode == 0 {
+			tc.stdCode = tc.code
+		}
+
+		assert.Equal(t, tc.stdCode, stdRw.Code, tc.path)
+
+		if rw.Code == http.StatusOK && stdRw.Code == http.StatusOK {
+			assert.Equal(t, stdRw.Body.String(), rw.Body.String(), tc.path)
+		}
+	}
+}
+
+func TestServer_ServeHTTP_stripPrefix(t *testing.T) {
+	sub, err := fs.Sub(v, "testdata")
+	require.NoError(t, err)
+
+	s := http.StripPrefix("/static/", statigz.FileServer(sub))
+
+	for u, expected := range map[string][2]string{
+		"/static/swagger.json":              {"200", ""},
+		"/static/":                          {"200", ""},
+		"/static/deeper/../swagger.json":    {"301", "/static/swagger.json"},
+		"/static/deeper/.//openapi.json":    {"301", "/static/deeper/openapi.json"},
+		"/static/deeper/../deeper?a=b":      {"301", "/static/deeper?a=b"},
+		"/static/deeper/../../swagger.json": {"400", ""},
+	} {
+		req := httptest.NewRequest(http.MethodGet, u, nil)
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		assert.Equal(t, expected[0], strconv.Itoa(rw.Code), u)
+		assert.Equal(t, expected[1], rw.Header().Get("Location"), u)
+	}
+
+	// Redirect does not lead to another host.
+	s = http.StripPrefix("/", statigz.FileServer(sub))
+
+	for u, expected := range map[string][2]string{
+		"//evil.com/.":        {"301", "/evil.com"},
+		"//evil.com/deeper/.": {"301", "/evil.com/deeper"},
+		"/deeper/./":          {"301", "/deeper/"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, u, nil)
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		assert.Equal(t, expected[0], strconv.Itoa(rw.Code), u)
+		assert.Equal(t, expected[1], rw.Header().Get("Location"), u)
+	}
+}
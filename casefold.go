@@ -0,0 +1,80 @@
+package statigz
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// foldNames builds case-insensitive index of files, directories and aliases.
+//
+// Names that only differ in case can not be resolved unambiguously and result in error.
+func (s *Server) foldNames() error {
+	s.folded = make(map[string]string)
+
+	add := func(name string) error {
+		lower := strings.ToLower(name)
+
+		if existing, found := s.folded[lower]; found && existing != name {
+			return fmt.Errorf("case-insensitive collision of %q and %q", existing, name)
+		}
+
+		s.folded[lower] = name
+
+		return nil
+	}
+
+	for fn := range s.info {
+		if err := add(fn); err != nil {
+			return err
+		}
+	}
+
+	for name := range s.logicalNames() {
+		if err := add(name); err != nil {
+			return err
+		}
+	}
+
+	for alias := range s.aliases {
+		if err := add(alias); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// caseFold returns name with canonical case for a name that does not exist as is.
+func (s *Server) caseFold(fn string) (string, bool) {
+	if s.folded == nil {
+		return "", false
+	}
+
+	base := strings.TrimSuffix(fn, "/")
+	slash := fn[len(base):]
+
+	if _, found := s.info[base]; found {
+		return "", false
+	}
+
+	if _, found := s.primaryInfo(base); found {
+		return "", false
+	}
+
+	if _, found := s.aliases[base]; found {
+		return "", false
+	}
+
+	name, found := s.folded[strings.ToLower(base)]
+	if !found || !strings.HasPrefix(name, s.fsPrefix) {
+		return "", false
+	}
+
+	return name + slash, true
+}
+
+// redirectCase redirects to URL path with canonical case.
+func (s *Server) redirectCase(rw http.ResponseWriter, req *http.Request, name string) {
+	s.redirectAbsolute(rw, req, "/"+strings.TrimPrefix(name, s.fsPrefix), http.StatusMovedPermanently)
+}
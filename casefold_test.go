@@ -0,0 +1,81 @@
+package statigz_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+var mixedCaseFS = fstest.MapFS{
+	"Images/Logo.PNG":  {Data: []byte("logo")},
+	"Docs/index.html":  {Data: []byte("docs")},
+	"static/App.js.gz": {Data: []byte("not a gzip")},
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	s := statigz.FileServer(mixedCaseFS, statigz.CaseInsensitive(false))
+
+	assertResponses(t, s, map[string][2]string{
+		"/images/logo.png": {"200", "logo"},
+		"/IMAGES/LOGO.PNG": {"200", "logo"},
+		"/Images/Logo.PNG": {"200", "logo"},
+		"/docs/":           {"200", "docs"},
+		"/docs":            {"301", "docs/"},
+		"/images/logo.gif": {"404", ""},
+	})
+
+	req, err := http.NewRequest(http.MethodGet, "/images/LOGO.png", nil)
+	require.NoError(t, err)
+	assert.True(t, s.Found(req))
+
+	// Encoded variant is found by logical name.
+	req, err = http.NewRequest(http.MethodHead, "/STATIC/app.js", nil)
+	require.NoError(t, err)
+
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "gzip", rw.Header().Get("Content-Encoding"))
+}
+
+func TestCaseInsensitive_redirect(t *testing.T) {
+	s := statigz.FileServer(mixedCaseFS, statigz.CaseInsensitive(true))
+
+	assertResponses(t, s, map[string][2]string{
+		"/images/logo.png?v=1": {"301", "/Images/Logo.PNG?v=1"},
+		"/docs/":               {"301", "/Docs/"},
+		"/Images/Logo.PNG":     {"200", "logo"},
+	})
+}
+
+func TestCaseInsensitive_collision(t *testing.T) {
+	defer func() {
+		err, ok := recover().(error)
+		require.True(t, ok)
+		assert.Contains(t, err.Error(), "case-insensitive collision of")
+	}()
+
+	statigz.FileServer(fstest.MapFS{
+		"README.md": {Data: []byte("a")},
+		"readme.md": {Data: []byte("b")},
+	}, statigz.CaseInsensitive(false))
+}
+
+func TestCaseInsensitive_stripPrefix(t *testing.T) {
+	s := http.StripPrefix("/static/", statigz.FileServer(mixedCaseFS, statigz.CaseInsensitive(true)))
+
+	req := httptest.NewRequest(http.MethodGet, "/static/images/logo.png?v=1", nil)
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusMovedPermanently, rw.Code)
+	assert.Equal(t, "/static/Images/Logo.PNG?v=1", rw.Header().Get("Location"))
+}
@@ -0,0 +1,120 @@
+package statigz
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig controls Cross-Origin Resource Sharing for served files.
+type CORSConfig struct {
+	// AllowedOrigins lists allowed origins, for example "https://example.com",
+	// "*" allows any origin, "https://*.example.com" allows any subdomain.
+	AllowedOrigins []string
+
+	// AllowOrigin is an optional custom check of origin, it is used in addition to AllowedOrigins.
+	AllowOrigin func(origin string) bool
+
+	// ExposedHeaders lists response headers available to cross-origin scripts, for example "Etag".
+	ExposedHeaders []string
+
+	// MaxAge is a duration of caching of preflight response.
+	MaxAge time.Duration
+}
+
+// CORS is an option to enable Cross-Origin Resource Sharing and preflight (OPTIONS) requests.
+func CORS(cfg CORSConfig) func(server *Server) {
+	return func(server *Server) {
+		server.CORS = &cfg
+	}
+}
+
+// anyOrigin checks if response does not depend on origin.
+func (c *CORSConfig) anyOrigin() bool {
+	if c.AllowOrigin != nil {
+		return false
+	}
+
+	for _, o := range c.AllowedOrigins {
+		if o == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *CORSConfig) allowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+
+	for _, o := range c.AllowedOrigins {
+		if o == "*" || o == origin {
+			return true
+		}
+
+		if i := strings.Index(o, "*"); i != -1 {
+			prefix, suffix := o[:i], o[i+1:]
+
+			if len(origin) > len(prefix)+len(suffix) &&
+				strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+				return true
+			}
+		}
+	}
+
+	return c.AllowOrigin != nil && c.AllowOrigin(origin)
+}
+
+// serveCORS adds CORS headers and responds to OPTIONS requests, it returns true if response is complete.
+func (s *Server) serveCORS(rw http.ResponseWriter, req *http.Request) bool {
+	c := s.CORS
+	origin := req.Header.Get("Origin")
+	allowed := c.allowed(origin)
+
+	if c.anyOrigin() {
+		rw.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		rw.Header().Add("Vary", "Origin")
+
+		if allowed {
+			rw.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+	}
+
+	if req.Method != http.MethodOptions {
+		if allowed && len(c.ExposedHeaders) > 0 {
+			rw.Header().Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+		}
+
+		return false
+	}
+
+	if _, found := s.found(req); !found {
+		rw.Header().Del("Access-Control-Allow-Origin")
+		s.notFound(rw, req)
+
+		return true
+	}
+
+	rw.Header().Set("Allow", http.MethodGet+", "+http.MethodHead+", "+http.MethodOptions)
+
+	m := req.Header.Get("Access-Control-Request-Method")
+	if allowed && (m == http.MethodGet || m == http.MethodHead) {
+		rw.Header().Set("Access-Control-Allow-Methods", http.MethodGet+", "+http.MethodHead)
+
+		if h := req.Header.Get("Access-Control-Request-Headers"); h != "" {
+			rw.Header().Set("Access-Control-Allow-Headers", h)
+		}
+
+		if c.MaxAge > 0 {
+			rw.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge.Seconds())))
+		}
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+
+	return true
+}
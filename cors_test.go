@@ -0,0 +1,95 @@
+package statigz_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+func TestCORS(t *testing.T) {
+	s := statigz.FileServer(v, statigz.FSPrefix("testdata"), statigz.CORS(statigz.CORSConfig{
+		AllowedOrigins: []string{"https://example.com", "https://*.example.org"},
+		AllowOrigin: func(origin string) bool {
+			return origin == "http://localhost:8080"
+		},
+		ExposedHeaders: []string{"Etag"},
+		MaxAge:         time.Hour,
+	}))
+
+	// Preflight.
+	req, err := http.NewRequest(http.MethodOptions, "/swagger.json", nil)
+	require.NoError(t, err)
+
+	req.Header.Set("Origin", "https://docs.example.org")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	req.Header.Set("Access-Control-Request-Headers", "Range")
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusNoContent, rw.Code)
+	assert.Equal(t, "https://docs.example.org", rw.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, HEAD", rw.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Range", rw.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "3600", rw.Header().Get("Access-Control-Max-Age"))
+	assert.Equal(t, "Origin", rw.Header().Get("Vary"))
+
+	// Preflight for missing file.
+	req.URL.Path = "/missing.json"
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+	assert.Equal(t, "", rw.Header().Get("Access-Control-Allow-Origin"))
+
+	// Simple request.
+	for origin, allowed := range map[string]bool{
+		"https://example.com":     true,
+		"http://localhost:8080":   true,
+		"https://a.example.org":   true,
+		"https://example.org":     false,
+		"https://evil.com":        false,
+		"https://example.com.com": false,
+	} {
+		req, err := http.NewRequest(http.MethodGet, "/swagger.json", nil)
+		require.NoError(t, err)
+
+		req.Header.Set("Origin", origin)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		assert.Equal(t, http.StatusOK, rw.Code)
+		assert.Equal(t, "Origin, Accept-Encoding", strings.Join(rw.Header().Values("Vary"), ", "))
+
+		if allowed {
+			assert.Equal(t, origin, rw.Header().Get("Access-Control-Allow-Origin"), origin)
+			assert.Equal(t, "Etag", rw.Header().Get("Access-Control-Expose-Headers"), origin)
+		} else {
+			assert.Equal(t, "", rw.Header().Get("Access-Control-Allow-Origin"), origin)
+		}
+	}
+}
+
+func TestCORS_anyOrigin(t *testing.T) {
+	s := statigz.FileServer(v, statigz.CORS(statigz.CORSConfig{AllowedOrigins: []string{"*"}}))
+
+	req, err := http.NewRequest(http.MethodGet, "/testdata/swagger.json", nil)
+	require.NoError(t, err)
+
+	req.Header.Set("Origin", "https://example.com")
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "*", rw.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Accept-Encoding", rw.Header().Get("Vary"))
+}
@@ -0,0 +1,220 @@
+package statigz
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// digests contains cryptographic hashes of file contents, hashes that are not needed are nil.
+type digests struct {
+	sha256 []byte
+	sha384 []byte
+	sha512 []byte
+}
+
+func (d digests) empty() bool {
+	return d.sha256 == nil && d.sha384 == nil && d.sha512 == nil
+}
+
+// digestWriter computes digests of written data.
+type digestWriter struct {
+	sha256 hash.Hash
+	sha384 hash.Hash
+	sha512 hash.Hash
+}
+
+// newDigestWriter creates digestWriter for identity content, it computes digests of
+// Repr-Digest if ReprDigest is enabled and digest of IntegrityHash.
+func (s *Server) newDigestWriter() *digestWriter {
+	d := &digestWriter{}
+
+	if s.ReprDigest || s.IntegrityHash == "sha256" {
+		d.sha256 = sha256.New()
+	}
+
+	if s.IntegrityHash == "sha384" {
+		d.sha384 = sha512.New384()
+	}
+
+	if s.ReprDigest || s.IntegrityHash == "sha512" {
+		d.sha512 = sha512.New()
+	}
+
+	return d
+}
+
+func (d *digestWriter) Write(p []byte) (int, error) {
+	for _, h := range []hash.Hash{d.sha256, d.sha384, d.sha512} {
+		if h != nil {
+			h.Write(p) //nolint:errcheck // Hash writes never fail.
+		}
+	}
+
+	return len(p), nil
+}
+
+func (d *digestWriter) digests() digests {
+	sum := func(h hash.Hash) []byte {
+		if h == nil {
+			return nil
+		}
+
+		return h.Sum(nil)
+	}
+
+	return digests{
+		sha256: sum(d.sha256),
+		sha384: sum(d.sha384),
+		sha512: sum(d.sha512),
+	}
+}
+
+// digestOf computes digests of identity content.
+func (s *Server) digestOf(b []byte) digests {
+	d := s.newDigestWriter()
+	_, _ = d.Write(b)
+
+	return d.digests()
+}
+
+// reprDigests computes digests of encoded content if they are needed for ReprDigest.
+func (s *Server) reprDigests(b []byte) digests {
+	if !s.ReprDigest {
+		return digests{}
+	}
+
+	return s.digestOf(b)
+}
+
+// hashIdentity computes digests of decoded contents for files that are only available encoded.
+//
+// Files that fail to decode are skipped, decoding error would be reported when serving such file.
+func (s *Server) hashIdentity() {
+	for fn, info := range s.info {
+		if info.isDir {
+			continue
+		}
+
+		for _, enc := range s.Encodings {
+			if enc.Decoder == nil || !strings.HasSuffix(fn, enc.FileExt) {
+				continue
+			}
+
+			base := strings.TrimSuffix(fn, enc.FileExt)
+
+			if _, found := s.info[base]; found {
+				continue
+			}
+
+			if _, found := s.identity[base]; found {
+				continue
+			}
+
+			d, err := s.decodedDigests(fn, info, enc)
+			if err != nil {
+				continue
+			}
+
+			s.identity[base] = d
+		}
+	}
+}
+
+func (s *Server) decodedDigests(fn string, info fileInfo, enc Encoding) (digests, error) {
+	r, err := s.reader(fn, info)
+	if err != nil {
+		return digests{}, err
+	}
+
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close() //nolint:errcheck // Read-only file.
+	}
+
+	r, err = enc.Decoder(r)
+	if err != nil {
+		return digests{}, err
+	}
+
+	d := s.newDigestWriter()
+
+	if _, err := io.Copy(d, r); err != nil {
+		return digests{}, err
+	}
+
+	return d.digests(), nil
+}
+
+// setDigestHeaders adds Repr-Digest and Content-Digest headers according to RFC 9530.
+//
+// Content-Digest is only added for requests without Range, as otherwise
+// content may be a part of representation.
+func setDigestHeaders(rw http.ResponseWriter, req *http.Request, d digests) {
+	if d.empty() {
+		return
+	}
+
+	rw.Header().Set("Repr-Digest", d.header(req.Header.Get("Want-Repr-Digest")))
+
+	if req.Header.Get("Range") == "" {
+		rw.Header().Set("Content-Digest", d.header(req.Header.Get("Want-Content-Digest")))
+	}
+}
+
+// header renders digests as a structured field dictionary,
+// want is an optional Want-Repr-Digest or Want-Content-Digest value with algorithm preferences.
+func (d digests) header(want string) string {
+	sha256Field := "sha-256=:" + base64.StdEncoding.EncodeToString(d.sha256) + ":"
+	sha512Field := "sha-512=:" + base64.StdEncoding.EncodeToString(d.sha512) + ":"
+
+	switch preferredDigest(want) {
+	case "sha-256":
+		return sha256Field
+	case "sha-512":
+		return sha512Field
+	default:
+		return sha256Field + ", " + sha512Field
+	}
+}
+
+// preferredDigest returns supported algorithm with the highest preference, or empty string
+// if there is no preference.
+//
+// Want-*-Digest is a dictionary of algorithms and their preferences in range 0-10,
+// for example "sha-256=1, sha-512=3", 0 means algorithm is not acceptable.
+func preferredDigest(want string) string {
+	var (
+		best     string
+		bestPref int
+	)
+
+	for _, item := range strings.Split(want, ",") {
+		kv := strings.SplitN(strings.TrimSpace(item), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		alg := strings.ToLower(strings.TrimSpace(kv[0]))
+		if alg != "sha-256" && alg != "sha-512" {
+			continue
+		}
+
+		pref, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil || pref <= 0 {
+			continue
+		}
+
+		// Stronger algorithm wins a tie.
+		if pref > bestPref || (pref == bestPref && alg == "sha-512") {
+			best = alg
+			bestPref = pref
+		}
+	}
+
+	return best
+}
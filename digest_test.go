@@ -0,0 +1,99 @@
+package statigz_test
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+	"github.com/vearutop/statigz/brotli"
+)
+
+func sha256Field(b []byte) string {
+	h := sha256.Sum256(b)
+
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(h[:]) + ":"
+}
+
+func sha512Field(b []byte) string {
+	h := sha512.Sum512(b)
+
+	return "sha-512=:" + base64.StdEncoding.EncodeToString(h[:]) + ":"
+}
+
+func TestServer_ServeHTTP_reprDigest(t *testing.T) {
+	s := statigz.FileServer(v, brotli.AddEncoding, statigz.EncodeOnInit, statigz.ReprDigest)
+
+	raw, err := os.ReadFile("testdata/swagger.json")
+	require.NoError(t, err)
+
+	// Identity representation.
+	req, err := http.NewRequest(http.MethodGet, "/testdata/swagger.json", nil)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, sha256Field(raw)+", "+sha512Field(raw), rw.Header().Get("Repr-Digest"))
+	assert.Equal(t, sha256Field(raw)+", "+sha512Field(raw), rw.Header().Get("Content-Digest"))
+
+	// Encoded representation with algorithm preference.
+	req.Header.Set("Accept-Encoding", "br")
+	req.Header.Set("Want-Repr-Digest", "sha-256=10, sha-512=3")
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, "br", rw.Header().Get("Content-Encoding"))
+	assert.Equal(t, sha256Field(rw.Body.Bytes()), rw.Header().Get("Repr-Digest"))
+	assert.Equal(t, sha256Field(rw.Body.Bytes())+", "+sha512Field(rw.Body.Bytes()), rw.Header().Get("Content-Digest"))
+
+	// Range request does not have Content-Digest.
+	req.Header.Set("Range", "bytes=0-10")
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusPartialContent, rw.Code)
+	assert.NotEmpty(t, rw.Header().Get("Repr-Digest"))
+	assert.Empty(t, rw.Header().Get("Content-Digest"))
+}
+
+func TestServer_ServeHTTP_reprDigest_decoded(t *testing.T) {
+	s := statigz.FileServer(v, brotli.AddEncoding, statigz.ReprDigest)
+
+	raw, err := os.ReadFile("testdata/swagger.json")
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "/testdata/deeper/swagger.json", nil)
+	require.NoError(t, err)
+
+	req.Header.Set("Want-Repr-Digest", "sha-256=0, sha-512=1")
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "", rw.Header().Get("Content-Encoding"))
+	assert.Equal(t, sha512Field(raw), rw.Header().Get("Repr-Digest"))
+}
+
+func TestServer_ServeHTTP_reprDigest_disabled(t *testing.T) {
+	s := statigz.FileServer(v)
+
+	req, err := http.NewRequest(http.MethodGet, "/testdata/swagger.json", nil)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Empty(t, rw.Header().Get("Repr-Digest"))
+}
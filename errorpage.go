@@ -0,0 +1,23 @@
+package statigz
+
+import (
+	"net/http"
+	"strings"
+)
+
+// serveErrorPage serves a file from ErrorPages with response status, it returns false if page is not available.
+func (s *Server) serveErrorPage(rw http.ResponseWriter, req *http.Request, status int) bool {
+	page, found := s.ErrorPages[status]
+	if !found {
+		return false
+	}
+
+	// Headers of requested file are not applicable to error page.
+	deleteFileHeaders(rw.Header())
+
+	if !strings.Contains(strings.Join(rw.Header().Values("Vary"), ","), "Accept-Encoding") {
+		rw.Header().Add("Vary", "Accept-Encoding")
+	}
+
+	return s.serveFile(rw, req, status, s.fsPrefix+strings.TrimPrefix(page, "/"))
+}
@@ -0,0 +1,90 @@
+package statigz_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+func TestErrorPages(t *testing.T) {
+	s := statigz.FileServer(fstest.MapFS{
+		"index.html":         {Data: []byte("home")},
+		"errors/404.html":    {Data: []byte("not found page")},
+		"errors/404.html.gz": {Data: gzipped(t, "compressed not found page")},
+		"errors/500.html":    {Data: []byte("error page")},
+		"broken.txt.gz":      {Data: []byte("not a gzip")},
+	}, statigz.ErrorPages(map[int]string{404: "errors/404.html", 500: "/errors/500.html"}))
+
+	req, err := http.NewRequest(http.MethodGet, "/missing.txt", nil)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rw.Header().Get("Content-Type"))
+	assert.Equal(t, "", rw.Header().Get("Etag"))
+	assert.Equal(t, "not found page", rw.Body.String())
+
+	// Content-Type of error page does not depend on requested path.
+	req, err = http.NewRequest(http.MethodGet, "/missing.js", nil)
+	require.NoError(t, err)
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rw.Header().Get("Content-Type"))
+
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("If-None-Match", "anything")
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+	assert.Equal(t, "gzip", rw.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", rw.Header().Get("Vary"))
+
+	r, err := gzip.NewReader(rw.Body)
+	require.NoError(t, err)
+
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "compressed not found page", string(b))
+
+	req, err = http.NewRequest(http.MethodGet, "/broken.txt", nil)
+	require.NoError(t, err)
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rw.Code)
+	assert.Equal(t, "error page", rw.Body.String())
+
+	req, err = http.NewRequest(http.MethodHead, "/missing.txt", nil)
+	require.NoError(t, err)
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+	assert.Equal(t, "", rw.Body.String())
+
+	// No page configured for 405.
+	req, err = http.NewRequest(http.MethodPost, "/", nil)
+	require.NoError(t, err)
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+	assert.Equal(t, "Method Not Allowed\n\nmethod should be GET or HEAD\n", rw.Body.String())
+}
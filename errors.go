@@ -0,0 +1,117 @@
+package statigz
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// ServeStage is a stage of serving a file.
+type ServeStage string
+
+// Serving stages.
+const (
+	// StageOpen is opening of file variant.
+	StageOpen ServeStage = "open"
+
+	// StageDecode is decoding of compressed variant for an agent that does not accept its encoding.
+	StageDecode ServeStage = "decode"
+
+	// StageCopy is copying of content into response.
+	StageCopy ServeStage = "copy"
+)
+
+// Sentinel errors of serving stages, they can be checked with errors.Is on ServeError.
+var (
+	ErrOpen   = errors.New("open failed")
+	ErrDecode = errors.New("decode failed")
+	ErrCopy   = errors.New("copy failed")
+)
+
+// ServeError describes a failure of serving a file, it is passed to OnError.
+type ServeError struct {
+	// Path is URL path of request.
+	Path string
+
+	// Variant is a name of served file in file system, for example "app.js.gz".
+	Variant string
+
+	// Encoding is a content encoding of variant, empty for uncompressed file.
+	Encoding string
+
+	// Stage is a stage of serving that failed.
+	Stage ServeStage
+
+	// HeaderWritten is true if response status and headers were already sent,
+	// so that error status can not be written anymore.
+	HeaderWritten bool
+
+	// Err is an underlying error.
+	Err error
+}
+
+// Error returns error message.
+func (e *ServeError) Error() string {
+	return string(e.Stage) + " " + e.Variant + ": " + e.Err.Error()
+}
+
+// Unwrap returns underlying error.
+func (e *ServeError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches sentinel error of serving stage.
+func (e *ServeError) Is(target error) bool {
+	switch e.Stage {
+	case StageOpen:
+		return target == ErrOpen
+	case StageDecode:
+		return target == ErrDecode
+	case StageCopy:
+		return target == ErrCopy
+	default:
+		return false
+	}
+}
+
+// CanWriteStatus returns false if error is a ServeError that occurred after response headers were sent.
+func CanWriteStatus(err error) bool {
+	var se *ServeError
+
+	return !errors.As(err, &se) || !se.HeaderWritten
+}
+
+// serveError creates ServeError for a variant of requested file.
+func (s *Server) serveError(req *http.Request, variant, suf string, stage ServeStage, err error) *ServeError {
+	e := &ServeError{
+		Path:    req.URL.Path,
+		Variant: variant,
+		Stage:   stage,
+		Err:     err,
+	}
+
+	for _, enc := range s.Encodings {
+		if suf != "" && enc.FileExt == suf {
+			e.Encoding = enc.ContentEncoding
+
+			break
+		}
+	}
+
+	return e
+}
+
+// trackedReader remembers read error to tell failures of content from failures of response writer.
+type trackedReader struct {
+	r   io.Reader
+	err error
+}
+
+func (t *trackedReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		t.err = err
+	}
+
+	return n, err
+}
@@ -0,0 +1,190 @@
+package statigz_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+// failingWriter fails to write response body.
+type failingWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errBroken
+}
+
+// switchFS starts failing to open files after broken is set.
+type switchFS struct {
+	files  fstest.MapFS
+	broken bool
+}
+
+func (s *switchFS) Open(name string) (fs.File, error) {
+	if s.broken && name != "." {
+		return nil, errBroken
+	}
+
+	return s.files.Open(name)
+}
+
+func serveError(t *testing.T, s *statigz.Server, rw http.ResponseWriter, u, ae string) error {
+	t.Helper()
+
+	var serveErr error
+
+	s.OnError = func(rw http.ResponseWriter, r *http.Request, err error) {
+		serveErr = err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	require.NoError(t, err)
+
+	req.Header.Set("Accept-Encoding", ae)
+	s.ServeHTTP(rw, req)
+
+	return serveErr
+}
+
+func TestServeError(t *testing.T) {
+	// Valid gzip header with corrupted body.
+	corrupted := gzipped(t, strings.Repeat("corrupted", 100))
+	corrupted = append(corrupted[:20:20], make([]byte, 100)...)
+
+	files := &switchFS{files: fstest.MapFS{
+		"corrupted.js.gz": {Data: corrupted},
+		"valid.js.gz":     {Data: gzipped(t, "valid")},
+	}}
+
+	s := statigz.FileServer(files)
+
+	err := serveError(t, s, httptest.NewRecorder(), "/corrupted.js", "")
+	assert.True(t, errors.Is(err, statigz.ErrDecode))
+	assert.False(t, errors.Is(err, statigz.ErrCopy))
+
+	// Client disconnect.
+	err = serveError(t, s, failingWriter{httptest.NewRecorder()}, "/valid.js", "")
+	assert.True(t, errors.Is(err, statigz.ErrCopy))
+	assert.False(t, errors.Is(err, statigz.ErrDecode))
+	assert.True(t, errors.Is(err, errBroken))
+
+	files.broken = true
+
+	err = serveError(t, s, httptest.NewRecorder(), "/corrupted.js", "gzip")
+	assert.True(t, errors.Is(err, statigz.ErrOpen))
+	assert.True(t, errors.Is(err, errBroken))
+
+	var se *statigz.ServeError
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, statigz.ServeError{
+		Path:     "/corrupted.js",
+		Variant:  "corrupted.js.gz",
+		Encoding: "gzip",
+		Stage:    statigz.StageOpen,
+		Err:      errBroken,
+	}, *se)
+}
+
+func TestServeError_fileHeaders(t *testing.T) {
+	files := &switchFS{files: fstest.MapFS{
+		"app.js":    {Data: []byte("app")},
+		"app.js.gz": {Data: gzipped(t, "app")},
+	}}
+
+	s := statigz.FileServer(files, statigz.ReprDigest, statigz.CacheControl(
+		statigz.CacheRule{Pattern: "*.js", Value: "public, max-age=3600"},
+	))
+
+	files.broken = true
+
+	for _, ae := range []string{"", "gzip"} {
+		req, err := http.NewRequest(http.MethodGet, "/app.js", nil)
+		require.NoError(t, err)
+
+		req.Header.Set("Accept-Encoding", ae)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rw.Code, ae)
+
+		// Headers of file are not applicable to error response.
+		for _, h := range []string{"Cache-Control", "Etag", "Content-Encoding", "Repr-Digest", "Content-Digest"} {
+			assert.Empty(t, rw.Header().Get(h), h)
+		}
+
+		assert.NotEqual(t, "3", rw.Header().Get("Content-Length"), ae)
+	}
+}
+
+func TestServeError_headerWritten(t *testing.T) {
+	content := bytes.NewBuffer(nil)
+	for i := 0; i < 20000; i++ {
+		content.WriteString(strconv.Itoa(i * i))
+	}
+
+	gz := gzipped(t, content.String())
+
+	s := statigz.FileServer(fstest.MapFS{
+		"truncated.txt.gz": {Data: gz[:len(gz)/2]},
+	})
+
+	var serveErr error
+
+	onError := s.OnError
+	s.OnError = func(rw http.ResponseWriter, r *http.Request, err error) {
+		serveErr = err
+
+		onError(rw, r, err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/truncated.txt", nil)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.True(t, errors.Is(serveErr, statigz.ErrDecode))
+	assert.False(t, statigz.CanWriteStatus(serveErr))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.NotContains(t, rw.Body.String(), "Internal Server Error")
+	assert.True(t, strings.HasPrefix(content.String(), rw.Body.String()))
+}
+
+func TestServeError_canceled(t *testing.T) {
+	s := statigz.FileServer(fstest.MapFS{
+		"app.js.gz": {Data: gzipped(t, "app")},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/app.js", nil)
+	require.NoError(t, err)
+
+	var serveErr error
+
+	s.OnError = func(rw http.ResponseWriter, r *http.Request, err error) {
+		serveErr = err
+	}
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.True(t, errors.Is(serveErr, context.Canceled))
+	assert.True(t, errors.Is(serveErr, statigz.ErrCopy))
+	assert.True(t, statigz.CanWriteStatus(serveErr))
+	assert.Empty(t, rw.Body.String())
+}
@@ -22,7 +22,7 @@ func ExampleFileServer() {
 	}
 
 	// Plug static assets handler to your server or router.
-	err = http.ListenAndServe(":80", statigz.FileServer(s.(fs.ReadDirFS), brotli.AddEncoding))
+	err = http.ListenAndServe(":80", statigz.FileServer(s, brotli.AddEncoding))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,188 @@
+package statigz
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const (
+	// fingerprintLen is a number of hash characters in fingerprinted file name.
+	fingerprintLen = 8
+
+	// immutableCacheControl is a Cache-Control value for content that never changes under the same URL.
+	immutableCacheControl = "public, max-age=31536000, immutable"
+)
+
+// logicalName returns name of a file as it is requested over HTTP, without encoding extension.
+func (s *Server) logicalName(fn string) string {
+	for _, enc := range s.Encodings {
+		if !strings.HasSuffix(fn, enc.FileExt) {
+			continue
+		}
+
+		base := strings.TrimSuffix(fn, enc.FileExt)
+		if _, found := s.info[base]; found || enc.Decoder != nil {
+			return base
+		}
+	}
+
+	return fn
+}
+
+// logicalNames returns names of all servable files.
+func (s *Server) logicalNames() map[string]bool {
+	names := make(map[string]bool, len(s.info))
+
+	for fn, info := range s.info {
+		if info.isDir {
+			continue
+		}
+
+		names[s.logicalName(fn)] = true
+	}
+
+	return names
+}
+
+// primaryInfo returns information of identity file, or of the first available encoded file.
+func (s *Server) primaryInfo(name string) (fileInfo, bool) {
+	if info, found := s.info[name]; found {
+		return info, !info.isDir
+	}
+
+	for _, enc := range s.Encodings {
+		if info, found := s.info[name+enc.FileExt]; found && !info.isDir {
+			return info, true
+		}
+	}
+
+	return fileInfo{}, false
+}
+
+// fingerprinted inserts a part of hash in file name before extension, e.g. "app.js" becomes "app.1bp69hxb.js".
+func fingerprinted(name, hash string) string {
+	if len(hash) > fingerprintLen {
+		hash = hash[:fingerprintLen]
+	}
+
+	dir, base := path.Split(name)
+	ext := path.Ext(base)
+
+	if ext == base {
+		ext = ""
+	}
+
+	return dir + strings.TrimSuffix(base, ext) + "." + hash + ext
+}
+
+func (s *Server) fingerprintFiles() {
+	s.fingerprints = make(map[string]string)
+	s.aliases = make(map[string]string)
+
+	for name := range s.logicalNames() {
+		s.fingerprint(name)
+	}
+}
+
+// fingerprint (re)creates fingerprinted alias for a file.
+func (s *Server) fingerprint(name string) {
+	if alias, found := s.fingerprints[name]; found {
+		delete(s.aliases, alias)
+		delete(s.fingerprints, name)
+	}
+
+	info, found := s.primaryInfo(name)
+	if !found {
+		return
+	}
+
+	alias := fingerprinted(name, info.hash)
+
+	// Real files take precedence over aliases.
+	if _, found := s.primaryInfo(alias); found {
+		return
+	}
+
+	s.fingerprints[name] = alias
+	s.aliases[alias] = name
+}
+
+// aliased checks if request path is a fingerprinted alias.
+func (s *Server) aliased(req *http.Request) bool {
+	fn := s.fsPrefix + strings.TrimPrefix(req.URL.Path, "/")
+
+	if name, found := s.caseFold(fn); found {
+		fn = name
+	}
+
+	_, found := s.aliases[fn]
+
+	return found
+}
+
+// URL returns URL path to a file, fingerprinted if Fingerprint option is enabled
+// and prefixed with URLPrefix.
+//
+// Name is relative to served root, leading "/" is optional, files of mounts are named with mount path,
+// for example "docs/guide.css".
+func (s *Server) URL(name string) (string, error) {
+	if m, rel, found := s.mountOf("/" + strings.TrimPrefix(name, "/")); found {
+		return m.server.URL(rel)
+	}
+
+	fn := s.fsPrefix + strings.TrimPrefix(name, "/")
+
+	if _, found := s.primaryInfo(fn); !found || !s.listed(fn) {
+		return "", fmt.Errorf("%w: %s", fs.ErrNotExist, name)
+	}
+
+	if alias, found := s.fingerprints[fn]; found {
+		fn = alias
+	}
+
+	return s.urlPrefix + "/" + strings.TrimPrefix(fn, s.fsPrefix), nil
+}
+
+// Manifest returns URL paths of all served files keyed by file names, for example
+// "app.js" => "/app.1bp69hxb.js".
+func (s *Server) Manifest() map[string]string {
+	m := make(map[string]string)
+
+	for name := range s.logicalNames() {
+		if !strings.HasPrefix(name, s.fsPrefix) {
+			continue
+		}
+
+		name = strings.TrimPrefix(name, s.fsPrefix)
+
+		// File is shadowed by mount.
+		if _, _, found := s.mountOf("/" + name); found {
+			continue
+		}
+
+		u, err := s.URL(name)
+		if err != nil {
+			continue
+		}
+
+		m[name] = u
+	}
+
+	for _, mt := range s.mounts {
+		for name, u := range mt.server.Manifest() {
+			name = strings.TrimPrefix(strings.TrimSuffix(mt.path, "/")+"/"+name, "/")
+
+			// Longer mount path takes precedence.
+			if owner, _, _ := s.mountOf("/" + name); owner.path != mt.path {
+				continue
+			}
+
+			m[name] = u
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,117 @@
+package statigz_test
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+	"github.com/vearutop/statigz/brotli"
+)
+
+func TestServer_URL(t *testing.T) {
+	s := statigz.FileServer(v, brotli.AddEncoding, statigz.Fingerprint, statigz.FSPrefix("testdata"))
+
+	u, err := s.URL("swagger.json")
+	require.NoError(t, err)
+	assert.Equal(t, "/swagger.1bp69hxb.json", u)
+
+	u, err = s.URL("/deeper/swagger.json")
+	require.NoError(t, err)
+	assert.Equal(t, "/deeper/swagger.3b88egjd.json", u)
+
+	_, err = s.URL("nonexistent.js")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	m := s.Manifest()
+	assert.Equal(t, "/swagger.1bp69hxb.json", m["swagger.json"])
+	assert.Equal(t, "/deeper/swagger.3b88egjd.json", m["deeper/swagger.json"])
+	assert.Contains(t, m, "deeper/openapi.json")
+	assert.Contains(t, m, "index.html")
+	assert.NotContains(t, m, "index.html.gz")
+	assert.NotContains(t, m, "deeper")
+
+	for _, u := range m {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		require.NoError(t, err)
+
+		req.Header.Set("Accept-Encoding", "gzip, br")
+
+		assert.True(t, s.Found(req), u)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		if u == m["bad.png"] { // Corrupted file.
+			continue
+		}
+
+		assert.Equal(t, http.StatusOK, rw.Code, u)
+		assert.Equal(t, "public, max-age=31536000, immutable", rw.Header().Get("Cache-Control"), u)
+	}
+
+	// Plain names are still available.
+	req, err := http.NewRequest(http.MethodGet, "/swagger.json", nil)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "", rw.Header().Get("Cache-Control"))
+}
+
+func TestServer_URL_noFingerprint(t *testing.T) {
+	s := statigz.FileServer(v, statigz.FSPrefix("testdata"))
+
+	u, err := s.URL("swagger.json")
+	require.NoError(t, err)
+	assert.Equal(t, "/swagger.json", u)
+	assert.Equal(t, "/deeper/openapi.json", s.Manifest()["deeper/openapi.json"])
+}
+
+func TestServer_URL_failedCacheControl(t *testing.T) {
+	s := statigz.FileServer(v, statigz.Fingerprint, statigz.FSPrefix("testdata"))
+
+	for u, code := range map[string]int{
+		"swagger.json": http.StatusOK,
+		"bad.png":      http.StatusInternalServerError,
+	} {
+		alias, err := s.URL(u)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodGet, alias, nil)
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		assert.Equal(t, code, rw.Code, u)
+
+		if code == http.StatusOK {
+			assert.Equal(t, "public, max-age=31536000, immutable", rw.Header().Get("Cache-Control"), u)
+		} else {
+			assert.Equal(t, "", rw.Header().Get("Cache-Control"), u)
+		}
+	}
+
+	// Alias of denied file is not found and not cached.
+	alias, err := s.URL("swagger.json")
+	require.NoError(t, err)
+
+	s = statigz.FileServer(v, statigz.Fingerprint, statigz.FSPrefix("testdata"),
+		statigz.Access(statigz.AccessPolicy{Deny: []string{"*.json"}}))
+
+	req, err := http.NewRequest(http.MethodGet, alias, nil)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+	assert.Equal(t, "", rw.Header().Get("Cache-Control"))
+}
@@ -0,0 +1,76 @@
+package statigz_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+// openOnlyFS hides optional interfaces of file system.
+type openOnlyFS struct {
+	fs fs.FS
+}
+
+func (o openOnlyFS) Open(name string) (fs.File, error) {
+	return o.fs.Open(name)
+}
+
+func TestFileServer_fs(t *testing.T) {
+	s := statigz.FileServer(openOnlyFS{fs: fstest.MapFS{
+		"index.html":    {Data: []byte("home")},
+		"docs/a.txt":    {Data: []byte("a")},
+		"docs/b.txt.gz": {Data: gzipped(t, "b")},
+	}})
+
+	assertResponses(t, s, map[string][2]string{
+		"/":           {"200", "home"},
+		"/docs/a.txt": {"200", "a"},
+		"/docs/b.txt": {"200", "b"},
+		"/docs":       {"301", "docs/"},
+		"/c.txt":      {"404", ""},
+	})
+}
+
+func TestFileServer_zip(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	zw := zip.NewWriter(buf)
+
+	for name, content := range map[string]string{
+		"index.html":   "home",
+		"assets/a.css": "a",
+	} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	s := statigz.FileServer(zr)
+
+	assertResponses(t, s, map[string][2]string{
+		"/":             {"200", "home"},
+		"/assets/a.css": {"200", "a"},
+	})
+
+	req, err := http.NewRequest(http.MethodGet, "/assets/a.css", nil)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, "text/css; charset=utf-8", rw.Header().Get("Content-Type"))
+}
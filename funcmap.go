@@ -0,0 +1,87 @@
+package statigz
+
+import (
+	"html/template"
+	"path"
+	"strings"
+)
+
+// FuncMap returns html/template functions to refer Server assets.
+//
+//	asset "app.js"       URL path to a file, fingerprinted if enabled, e.g. "/app.1bp69hxb.js".
+//	integrity "app.js"   Subresource Integrity of a file, e.g. "sha384-oqVuAfXRKap7...".
+//	assetExists "app.js" true if file exists.
+//	preload "app.js"     preload link tag, e.g. <link rel="preload" href="/app.1bp69hxb.js" as="script" integrity="..." crossorigin="anonymous">.
+//
+// Functions fail template execution for unknown files.
+func FuncMap(s *Server) template.FuncMap {
+	return template.FuncMap{
+		"asset":     s.URL,
+		"integrity": s.Integrity,
+		"assetExists": func(name string) bool {
+			_, err := s.URL(name)
+
+			return err == nil
+		},
+		"preload": func(name string) (template.HTML, error) {
+			u, err := s.URL(name)
+			if err != nil {
+				return "", err
+			}
+
+			sri, err := s.Integrity(name)
+			if err != nil {
+				return "", err
+			}
+
+			tag := `<link rel="preload" href="` + template.HTMLEscapeString(u) + `"`
+
+			if as := preloadDestination(name); as != "" {
+				tag += ` as="` + as + `"`
+
+				if as == "font" || as == "fetch" {
+					tag += ` type="` + template.HTMLEscapeString(preloadType(name)) + `"`
+				}
+			}
+
+			tag += ` integrity="` + template.HTMLEscapeString(sri) + `" crossorigin="anonymous">`
+
+			return template.HTML(tag), nil //nolint:gosec // Values are escaped.
+		},
+	}
+}
+
+// preloadDestination returns value of "as" attribute of preload link for a file.
+func preloadDestination(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".js", ".mjs":
+		return "script"
+	case ".css":
+		return "style"
+	case ".woff", ".woff2", ".ttf", ".otf":
+		return "font"
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".avif", ".svg", ".ico":
+		return "image"
+	case ".json", ".wasm":
+		return "fetch"
+	default:
+		return ""
+	}
+}
+
+func preloadType(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".woff":
+		return "font/woff"
+	case ".woff2":
+		return "font/woff2"
+	case ".ttf":
+		return "font/ttf"
+	case ".otf":
+		return "font/otf"
+	case ".json":
+		return "application/json"
+	default:
+		return "application/wasm"
+	}
+}
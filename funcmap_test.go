@@ -0,0 +1,38 @@
+package statigz_test
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+func TestFuncMap(t *testing.T) {
+	s := statigz.FileServer(v, statigz.Fingerprint, statigz.FSPrefix("testdata"))
+
+	sri, err := s.Integrity("swagger.json")
+	require.NoError(t, err)
+
+	tpl, err := template.New("page").Funcs(statigz.FuncMap(s)).Parse(
+		`<script src="{{ asset "swagger.json" }}" integrity="{{ integrity "swagger.json" }}"></script>` +
+			`{{ if assetExists "missing.js" }}missing{{ end }}{{ if assetExists "index.html" }}index{{ end }}` +
+			`{{ preload "swagger.json" }}`)
+	require.NoError(t, err)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, tpl.Execute(buf, nil))
+
+	assert.Equal(t, `<script src="/swagger.1bp69hxb.json" integrity="`+sri+`"></script>index`+
+		`<link rel="preload" href="/swagger.1bp69hxb.json" as="fetch" type="application/json" integrity="`+sri+`" crossorigin="anonymous">`,
+		buf.String())
+
+	tpl, err = template.New("page").Funcs(statigz.FuncMap(s)).Parse(`<script src="{{ asset "ap.js" }}"></script>`)
+	require.NoError(t, err)
+
+	err = tpl.Execute(buf, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "file does not exist: ap.js")
+}
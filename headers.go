@@ -0,0 +1,129 @@
+package statigz
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// HeadersFile is a name of file in the root of file system with custom response headers.
+const HeadersFile = "_headers"
+
+// HeaderRule adds response headers for URL paths matching a pattern.
+//
+// Path is a URL path pattern with placeholders and an optional trailing splat, for example "/assets/*".
+type HeaderRule struct {
+	Path    string
+	Headers http.Header
+}
+
+// ParseHeaders parses rules in the format of Netlify _headers file.
+//
+// Every rule starts with a path pattern, followed by indented header lines,
+// empty lines and lines starting with "#" are ignored.
+//
+//	/*
+//	  X-Frame-Options: DENY
+//	/assets/*
+//	  Link: </assets/app.css>; rel=preload; as=style
+func ParseHeaders(r io.Reader) ([]HeaderRule, error) {
+	var (
+		rules []HeaderRule
+		line  int
+	)
+
+	sc := bufio.NewScanner(r)
+
+	for sc.Scan() {
+		line++
+
+		text := sc.Text()
+		trimmed := strings.TrimSpace(text)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// Path pattern is not indented.
+		if trimmed == text {
+			if err := validatePattern(trimmed); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+
+			rules = append(rules, HeaderRule{Path: trimmed, Headers: make(http.Header)})
+
+			continue
+		}
+
+		if len(rules) == 0 {
+			return nil, fmt.Errorf("line %d: %w", line, errors.New("header without path pattern"))
+		}
+
+		kv := strings.SplitN(trimmed, ":", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("line %d: invalid header %q", line, trimmed)
+		}
+
+		rules[len(rules)-1].Headers.Add(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// loadHeaders validates rules, reads rules from HeadersFile and removes it from served files.
+func (s *Server) loadHeaders() error {
+	for _, r := range s.Headers {
+		if err := validatePattern(r.Path); err != nil {
+			return fmt.Errorf("headers %s: %w", r.Path, err)
+		}
+	}
+
+	fn := s.fsPrefix + HeadersFile
+
+	info, found := s.info[fn]
+	if !found || info.isDir {
+		return nil
+	}
+
+	content, err := s.readAll(fn, info, nil)
+	if err != nil {
+		return err
+	}
+
+	rules, err := ParseHeaders(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", fn, err)
+	}
+
+	delete(s.info, fn)
+
+	s.Headers = append(rules, s.Headers...)
+
+	return nil
+}
+
+// setCustomHeaders adds headers of all rules matching URL path.
+func (s *Server) setCustomHeaders(h http.Header, urlPath string) {
+	for _, r := range s.Headers {
+		if _, ok := matchPath(r.Path, urlPath); !ok {
+			continue
+		}
+
+		for k, vv := range r.Headers {
+			k = textproto.CanonicalMIMEHeaderKey(k)
+
+			for _, v := range vv {
+				h.Add(k, v)
+			}
+		}
+	}
+}
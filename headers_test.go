@@ -0,0 +1,95 @@
+package statigz_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+func TestParseHeaders(t *testing.T) {
+	rules, err := statigz.ParseHeaders(strings.NewReader(`
+# Comment.
+/*
+  X-Frame-Options: DENY
+  Link: </a.css>; rel=preload
+  Link: </b.js>; rel=preload
+/docs/:page
+  X-Robots-Tag: noindex
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []statigz.HeaderRule{
+		{Path: "/*", Headers: http.Header{
+			"X-Frame-Options": {"DENY"},
+			"Link":            {"</a.css>; rel=preload", "</b.js>; rel=preload"},
+		}},
+		{Path: "/docs/:page", Headers: http.Header{"X-Robots-Tag": {"noindex"}}},
+	}, rules)
+
+	for src, msg := range map[string]string{
+		"  X-Frame-Options: DENY": "line 1: header without path pattern",
+		"/*\n  X-Frame-Options":   `line 2: invalid header "X-Frame-Options"`,
+		"docs":                    `line 1: path pattern must start with /: "docs"`,
+	} {
+		_, err := statigz.ParseHeaders(strings.NewReader(src))
+		assert.EqualError(t, err, msg, src)
+	}
+}
+
+func TestServer_ServeHTTP_headers(t *testing.T) {
+	s := statigz.FileServer(fstest.MapFS{
+		"_headers": {Data: []byte(`
+/*
+  X-Frame-Options: DENY
+/docs/*
+  X-Robots-Tag: noindex
+`)},
+		"index.html":     {Data: []byte("index")},
+		"docs/page.html": {Data: []byte("page")},
+	}, statigz.Headers(statigz.HeaderRule{
+		Path:    "/docs/page.html",
+		Headers: http.Header{"Link": {"</style.css>; rel=preload; as=style"}},
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "/docs/page.html", nil)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "DENY", rw.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "noindex", rw.Header().Get("X-Robots-Tag"))
+	assert.Equal(t, "</style.css>; rel=preload; as=style", rw.Header().Get("Link"))
+
+	req.Header.Set("If-None-Match", rw.Header().Get("Etag"))
+	req.Header.Set("Range", "bytes=0-1")
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusNotModified, rw.Code)
+	assert.Equal(t, "DENY", rw.Header().Get("X-Frame-Options"))
+
+	req.Header.Del("If-None-Match")
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusPartialContent, rw.Code)
+	assert.Equal(t, "noindex", rw.Header().Get("X-Robots-Tag"))
+
+	req, err = http.NewRequest(http.MethodGet, "/_headers", nil)
+	require.NoError(t, err)
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+	assert.Equal(t, "", rw.Header().Get("X-Frame-Options"))
+}
@@ -0,0 +1,94 @@
+package statigz
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// HostRouter is a http.Handler that serves requests with Server of requested host.
+//
+// Host is a part of cache key, so responses do not vary by Host header, but they vary by
+// X-Forwarded-Host if ForwardedHost is enabled.
+type HostRouter struct {
+	// Default serves requests of unknown hosts, unknown hosts are not found if Default is nil.
+	Default *Server
+
+	// ForwardedHost enables routing by X-Forwarded-Host header of a trusted reverse proxy.
+	ForwardedHost bool
+
+	hosts map[string]*Server
+}
+
+// Handle registers Server for a host name, "*.example.com" matches any subdomain of example.com.
+//
+// Servers should be registered before serving requests.
+func (r *HostRouter) Handle(host string, s *Server) {
+	if r.hosts == nil {
+		r.hosts = make(map[string]*Server)
+	}
+
+	r.hosts[normalizeHost(host)] = s
+}
+
+// Match returns Server for request host, or Default.
+//
+// Exact host name takes precedence over wildcards, more specific wildcards take precedence over less specific.
+func (r *HostRouter) Match(req *http.Request) *Server {
+	host := req.Host
+
+	if r.ForwardedHost {
+		if fh := req.Header.Get("X-Forwarded-Host"); fh != "" {
+			host = strings.TrimSpace(strings.Split(fh, ",")[0])
+		}
+	}
+
+	host = normalizeHost(host)
+
+	if s, found := r.hosts[host]; found {
+		return s
+	}
+
+	for i := strings.Index(host, "."); i != -1; {
+		host = host[i+1:]
+
+		if s, found := r.hosts["*."+host]; found {
+			return s
+		}
+
+		i = strings.Index(host, ".")
+	}
+
+	return r.Default
+}
+
+// ServeHTTP serves request with Server of requested host.
+func (r *HostRouter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if r.ForwardedHost {
+		rw.Header().Add("Vary", "X-Forwarded-Host")
+	}
+
+	if s := r.Match(req); s != nil {
+		s.ServeHTTP(rw, req)
+
+		return
+	}
+
+	http.NotFound(rw, req)
+}
+
+// Found returns true if http.Request would be fulfilled by Server of requested host.
+func (r *HostRouter) Found(req *http.Request) bool {
+	s := r.Match(req)
+
+	return s != nil && s.Found(req)
+}
+
+// normalizeHost removes port and trailing dot and converts host name to lower case.
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
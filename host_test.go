@@ -0,0 +1,127 @@
+package statigz_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+func TestHostRouter(t *testing.T) {
+	var r statigz.HostRouter
+
+	site := func(name string) *statigz.Server {
+		return statigz.FileServer(fstest.MapFS{"index.html": {Data: []byte(name)}})
+	}
+
+	r.Handle("example.com", site("example"))
+	r.Handle("*.example.com", site("subdomain"))
+	r.Handle("*.docs.example.com", site("docs"))
+	r.Handle("Other.ORG", site("other"))
+
+	for host, body := range map[string]string{
+		"example.com":          "example",
+		"EXAMPLE.com:8080":     "example",
+		"example.com.":         "example",
+		"www.example.com":      "subdomain",
+		"a.b.example.com":      "subdomain",
+		"v1.docs.example.com":  "docs",
+		"docs.example.com":     "subdomain",
+		"other.org":            "other",
+		"www.other.org":        "",
+		"example.com.evil.org": "",
+	} {
+		req, err := http.NewRequest(http.MethodGet, "/", nil)
+		require.NoError(t, err)
+
+		req.Host = host
+
+		rw := httptest.NewRecorder()
+		r.ServeHTTP(rw, req)
+
+		if body == "" {
+			assert.Equal(t, http.StatusNotFound, rw.Code, host)
+			assert.False(t, r.Found(req), host)
+
+			continue
+		}
+
+		assert.Equal(t, http.StatusOK, rw.Code, host)
+		assert.Equal(t, body, rw.Body.String(), host)
+		assert.Equal(t, "Accept-Encoding", rw.Header().Get("Vary"), host)
+		assert.True(t, r.Found(req), host)
+	}
+
+	r.Default = site("default")
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, err)
+
+	req.Host = "unknown.net"
+
+	rw := httptest.NewRecorder()
+	r.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "default", rw.Body.String())
+}
+
+func TestHostRouter_forwardedHost(t *testing.T) {
+	r := statigz.HostRouter{ForwardedHost: true}
+	r.Handle("example.com", statigz.FileServer(fstest.MapFS{"index.html": {Data: []byte("example")}}))
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, err)
+
+	req.Host = "backend:8080"
+	req.Header.Set("X-Forwarded-Host", "example.com, proxy.local")
+
+	rw := httptest.NewRecorder()
+	r.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "example", rw.Body.String())
+	assert.Equal(t, []string{"X-Forwarded-Host", "Accept-Encoding"}, rw.Header().Values("Vary"))
+}
+
+func TestShareEncoded(t *testing.T) {
+	shared := []byte(strings.Repeat("body { color: red; }\n", 100))
+
+	var cache statigz.EncodedCache
+
+	a := statigz.FileServer(fstest.MapFS{
+		"style.css": {Data: shared},
+		"a.css":     {Data: []byte(strings.Repeat("a { color: blue; }\n", 100))},
+	}, statigz.EncodeOnInit, statigz.ShareEncoded(&cache))
+
+	b := statigz.FileServer(fstest.MapFS{
+		"common/style.css": {Data: shared},
+	}, statigz.EncodeOnInit, statigz.ShareEncoded(&cache))
+
+	assert.Equal(t, 2, cache.Len())
+
+	for _, tc := range []struct {
+		s *statigz.Server
+		u string
+	}{
+		{a, "/style.css"},
+		{b, "/common/style.css"},
+	} {
+		req, err := http.NewRequest(http.MethodGet, tc.u, nil)
+		require.NoError(t, err)
+
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		rw := httptest.NewRecorder()
+		tc.s.ServeHTTP(rw, req)
+
+		assert.Equal(t, http.StatusOK, rw.Code)
+		assert.Equal(t, "gzip", rw.Header().Get("Content-Encoding"))
+		assert.Equal(t, string(gzipped(t, string(shared))), rw.Body.String())
+	}
+}
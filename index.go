@@ -0,0 +1,122 @@
+package statigz
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// TrailingSlashPolicy defines redirects of directory paths.
+type TrailingSlashPolicy int
+
+const (
+	// TrailingSlashAlways redirects "/about" to "/about/" if "about" is a directory.
+	TrailingSlashAlways TrailingSlashPolicy = iota
+
+	// TrailingSlashNever redirects "/about/" to "/about" and serves directory index for "/about".
+	TrailingSlashNever
+
+	// TrailingSlashPreserve serves directory index for both "/about" and "/about/".
+	TrailingSlashPreserve
+)
+
+// cleanURLExt is an extension of files that are served without extension in URL path with CleanURLs.
+const cleanURLExt = ".html"
+
+// redirect redirects requests for index documents, clean URLs and directories to canonical paths.
+func (s *Server) redirect(rw http.ResponseWriter, req *http.Request, fn string) bool {
+	p := req.URL.Path
+
+	for _, idx := range s.IndexNames {
+		if !strings.HasSuffix(p, "/"+idx) {
+			continue
+		}
+
+		dir := strings.TrimSuffix(p, idx)
+
+		if s.TrailingSlash == TrailingSlashNever && dir != "/" {
+			s.redirectLocal(rw, req, "../"+path.Base(dir), strings.TrimSuffix(dir, "/"))
+		} else {
+			s.redirectLocal(rw, req, "./", dir)
+		}
+
+		return true
+	}
+
+	if s.CleanURLs && strings.HasSuffix(p, cleanURLExt) {
+		if _, found := s.primaryInfo(fn); found {
+			s.redirectLocal(rw, req, strings.TrimSuffix(path.Base(p), cleanURLExt), strings.TrimSuffix(p, cleanURLExt))
+
+			return true
+		}
+	}
+
+	dir := strings.TrimSuffix(fn, "/")
+	if !s.info[dir].isDir || s.cleanURL(fn) != "" {
+		return false
+	}
+
+	switch s.TrailingSlash {
+	case TrailingSlashAlways:
+		if !strings.HasSuffix(p, "/") {
+			s.redirectLocal(rw, req, path.Base(p)+"/", p+"/")
+
+			return true
+		}
+	case TrailingSlashNever:
+		if strings.HasSuffix(p, "/") && p != "/" {
+			s.redirectLocal(rw, req, "../"+path.Base(p), strings.TrimSuffix(p, "/"))
+
+			return true
+		}
+	case TrailingSlashPreserve:
+	}
+
+	return false
+}
+
+// cleanURL returns name of HTML document for a path name without extension, or empty string.
+func (s *Server) cleanURL(fn string) string {
+	if !s.CleanURLs || fn == "" || strings.HasSuffix(fn, "/") || path.Ext(fn) != "" {
+		return ""
+	}
+
+	if _, found := s.primaryInfo(fn + cleanURLExt); found {
+		return fn + cleanURLExt
+	}
+
+	return ""
+}
+
+// resolve returns name of a file to serve for a path name, it resolves directory index and clean URLs.
+//
+// If name points to a directory without index, name of directory with trailing "/" is returned.
+func (s *Server) resolve(fn string) (name string, isDir bool) {
+	dir := strings.TrimSuffix(fn, "/")
+
+	if dir == fn && fn != "" {
+		if _, found := s.primaryInfo(fn); found {
+			return fn, false
+		}
+
+		if html := s.cleanURL(fn); html != "" {
+			return html, false
+		}
+
+		if !s.info[fn].isDir {
+			return fn, false
+		}
+	}
+
+	if dir != "" {
+		dir += "/"
+	}
+
+	for _, idx := range s.IndexNames {
+		if _, found := s.primaryInfo(dir + idx); found {
+			return dir + idx, true
+		}
+	}
+
+	return dir, true
+}
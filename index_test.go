@@ -0,0 +1,84 @@
+package statigz_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+var siteFS = fstest.MapFS{
+	"index.htm":       {Data: []byte("home")},
+	"about.html":      {Data: []byte("about")},
+	"docs/index.htm":  {Data: []byte("docs")},
+	"blog/index.html": {Data: []byte("blog")},
+}
+
+func assertResponses(t *testing.T, s http.Handler, responses map[string][2]string) {
+	t.Helper()
+
+	for u, resp := range responses {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		switch resp[0] {
+		case "301":
+			assert.Equal(t, http.StatusMovedPermanently, rw.Code, u)
+			assert.Equal(t, resp[1], rw.Header().Get("Location"), u)
+		case "404":
+			assert.Equal(t, http.StatusNotFound, rw.Code, u)
+		default:
+			assert.Equal(t, http.StatusOK, rw.Code, u)
+			assert.Equal(t, resp[1], rw.Body.String(), u)
+		}
+	}
+}
+
+func TestIndexNames(t *testing.T) {
+	s := statigz.FileServer(siteFS, statigz.IndexNames("index.html", "index.htm"))
+
+	assertResponses(t, s, map[string][2]string{
+		"/":                {"200", "home"},
+		"/docs/":           {"200", "docs"},
+		"/blog/":           {"200", "blog"},
+		"/docs":            {"301", "docs/"},
+		"/docs/index.htm":  {"301", "./"},
+		"/blog/index.html": {"301", "./"},
+		"/about.html":      {"200", "about"},
+		"/about":           {"404", ""},
+	})
+}
+
+func TestCleanURLs(t *testing.T) {
+	s := statigz.FileServer(siteFS, statigz.IndexNames("index.html", "index.htm"),
+		statigz.CleanURLs, statigz.TrailingSlash(statigz.TrailingSlashNever))
+
+	assertResponses(t, s, map[string][2]string{
+		"/":                {"200", "home"},
+		"/about":           {"200", "about"},
+		"/about.html":      {"301", "about"},
+		"/docs":            {"200", "docs"},
+		"/docs/":           {"301", "../docs"},
+		"/docs/index.htm":  {"301", "../docs"},
+		"/blog/index.html": {"301", "../blog"},
+		"/index.htm":       {"301", "./"},
+	})
+}
+
+func TestTrailingSlashPreserve(t *testing.T) {
+	s := statigz.FileServer(siteFS, statigz.IndexNames("index.html", "index.htm"),
+		statigz.TrailingSlash(statigz.TrailingSlashPreserve))
+
+	assertResponses(t, s, map[string][2]string{
+		"/docs":  {"200", "docs"},
+		"/docs/": {"200", "docs"},
+		"/blog":  {"200", "blog"},
+	})
+}
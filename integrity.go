@@ -0,0 +1,53 @@
+package statigz
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// Integrity returns Subresource Integrity metadata for identity (not encoded) content of a file,
+// for example "sha384-oqVuAfXRKap7fdgcCY5uykM6+R9GqQ8K/uxy9rx7HNQlGYl1kPzQho1wx4JwY8wC".
+//
+// Hash algorithm is configured with IntegrityHash option.
+// Path is relative to served root, leading "/" is optional.
+// Files that are only available encoded are hashed by their decoded contents.
+func (s *Server) Integrity(path string) (string, error) {
+	if m, rel, found := s.mountOf("/" + strings.TrimPrefix(path, "/")); found {
+		return m.server.Integrity(rel)
+	}
+
+	fn := s.fsPrefix + strings.TrimPrefix(path, "/")
+
+	d, found := s.identityDigests(fn)
+	if !found || !s.listed(fn) {
+		return "", fmt.Errorf("%w: %s", fs.ErrNotExist, path)
+	}
+
+	var sum []byte
+
+	switch s.IntegrityHash {
+	case "sha256":
+		sum = d.sha256
+	case "sha384":
+		sum = d.sha384
+	case "sha512":
+		sum = d.sha512
+	default:
+		return "", fmt.Errorf("unsupported integrity hash: %q", s.IntegrityHash)
+	}
+
+	return s.IntegrityHash + "-" + base64.StdEncoding.EncodeToString(sum), nil
+}
+
+// identityDigests returns digests of identity content of a file.
+func (s *Server) identityDigests(fn string) (digests, bool) {
+	if info, found := s.info[fn]; found && !info.isDir {
+		return info.digests, true
+	}
+
+	d, found := s.identity[fn]
+
+	return d, found
+}
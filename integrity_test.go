@@ -0,0 +1,109 @@
+package statigz_test
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+	"github.com/vearutop/statigz/brotli"
+)
+
+func TestServer_Integrity(t *testing.T) {
+	s := statigz.FileServer(v, brotli.AddEncoding)
+
+	raw, err := os.ReadFile("testdata/swagger.json")
+	require.NoError(t, err)
+
+	sum384 := sha512.Sum384(raw)
+	expected := "sha384-" + base64.StdEncoding.EncodeToString(sum384[:])
+
+	sri, err := s.Integrity("testdata/swagger.json")
+	require.NoError(t, err)
+	assert.Equal(t, expected, sri)
+
+	// Decoded content of compressed-only file.
+	sri, err = s.Integrity("/testdata/deeper/swagger.json")
+	require.NoError(t, err)
+	assert.Equal(t, expected, sri)
+
+	_, err = s.Integrity("testdata/nonexistent.js")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	_, err = s.Integrity("testdata/deeper")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestIntegrityHash(t *testing.T) {
+	s := statigz.FileServer(v, statigz.IntegrityHash("sha256"), statigz.FSPrefix("testdata"))
+
+	raw, err := os.ReadFile("testdata/index.html")
+	require.NoError(t, err)
+
+	sum := sha256.Sum256(raw)
+
+	sri, err := s.Integrity("index.html")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256-"+base64.StdEncoding.EncodeToString(sum[:]), sri)
+
+	s = statigz.FileServer(v, statigz.IntegrityHash("md5"))
+	_, err = s.Integrity("testdata/index.html")
+	assert.EqualError(t, err, `unsupported integrity hash: "md5"`)
+}
+
+func TestServer_Integrity_indexing(t *testing.T) {
+	decoded := 0
+
+	s := statigz.FileServer(v, statigz.FSPrefix("testdata"), brotli.AddEncoding, func(server *statigz.Server) {
+		for i, enc := range server.Encodings {
+			dec := enc.Decoder
+
+			server.Encodings[i].Decoder = func(r io.Reader) (io.Reader, error) {
+				decoded++
+
+				return dec(r)
+			}
+		}
+	})
+
+	// Compressed-only files are decoded and hashed during indexing.
+	indexed := decoded
+	assert.NotZero(t, indexed)
+
+	raw, err := os.ReadFile("testdata/swagger.json")
+	require.NoError(t, err)
+
+	sum := sha512.Sum384(raw)
+
+	sri, err := s.Integrity("deeper/swagger.json")
+	require.NoError(t, err)
+	assert.Equal(t, "sha384-"+base64.StdEncoding.EncodeToString(sum[:]), sri)
+	assert.Equal(t, indexed, decoded)
+}
+
+func TestServer_Integrity_access(t *testing.T) {
+	s := statigz.FileServer(fstest.MapFS{
+		".env":       {Data: []byte("SECRET=1")},
+		"secret.bak": {Data: []byte("backup")},
+		"app.js":     {Data: []byte("app")},
+	}, statigz.Access(statigz.AccessPolicy{Deny: []string{"*.bak"}}))
+
+	for _, name := range []string{".env", "secret.bak"} {
+		_, err := s.Integrity(name)
+		assert.True(t, errors.Is(err, fs.ErrNotExist), name)
+
+		_, err = statigz.FuncMap(s)["integrity"].(func(string) (string, error))(name)
+		assert.True(t, errors.Is(err, fs.ErrNotExist), name)
+	}
+
+	_, err := s.Integrity("app.js")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,168 @@
+package statigz
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+)
+
+// listingEntry describes a file or a directory in a listing.
+type listingEntry struct {
+	Name  string `json:"name"`
+	IsDir bool   `json:"isDir,omitempty"`
+
+	// Size is a size of identity content, it is absent if file is only available encoded.
+	Size *int `json:"size,omitempty"`
+
+	// Encodings contains sizes of encoded contents by content encoding.
+	Encodings map[string]int `json:"encodings,omitempty"`
+}
+
+type listing struct {
+	Path    string         `json:"path"`
+	Entries []listingEntry `json:"entries"`
+
+	// Base is a prefix of relative links to entries, it is not empty for directory path without trailing slash.
+	Base string `json:"-"`
+}
+
+var listingTemplate = template.Must(template.New("listing").Parse(`<!doctype html>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width">
+<title>Index of {{ .Path }}</title>
+<h1>Index of {{ .Path }}</h1>
+<table>
+<tr><th>Name</th><th>Size</th><th>Encodings</th></tr>
+{{ range .Entries }}<tr><td><a href="{{ $.Base }}{{ .Name }}{{ if .IsDir }}/{{ end }}">{{ .Name }}{{ if .IsDir }}/{{ end }}</a></td>` +
+	`<td>{{ with .Size }}{{ . }}{{ end }}</td>` +
+	`<td>{{ range $enc, $size := .Encodings }}{{ $enc }}: {{ $size }} {{ end }}</td></tr>
+{{ end }}</table>
+`))
+
+// listingEnabled checks if directory listing is enabled for URL path.
+func (s *Server) listingEnabled(urlPath string) bool {
+	for _, prefix := range s.DirListing {
+		if strings.HasPrefix(urlPath, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// serveListing renders a list of files in directory, it returns false if listing is not available.
+func (s *Server) serveListing(rw http.ResponseWriter, req *http.Request, dir string) bool {
+	if !s.listingEnabled(req.URL.Path) {
+		return false
+	}
+
+	if dir != "." && !s.info[dir].isDir {
+		return false
+	}
+
+	l := listing{
+		Path:    s.urlPrefix + req.URL.Path,
+		Entries: s.listEntries(req, dir),
+	}
+
+	if !strings.HasSuffix(req.URL.Path, "/") {
+		l.Base = path.Base(req.URL.Path) + "/"
+	}
+
+	rw.Header().Add("Vary", "Accept")
+
+	if strings.Contains(req.Header.Get("Accept"), "application/json") {
+		rw.Header().Set("Content-Type", "application/json")
+
+		if req.Method != http.MethodHead {
+			cw := &committedWriter{ResponseWriter: rw}
+
+			if err := json.NewEncoder(cw).Encode(l); err != nil {
+				s.onListingError(rw, req, dir, cw, err)
+			}
+		}
+
+		return true
+	}
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if req.Method != http.MethodHead {
+		cw := &committedWriter{ResponseWriter: rw}
+
+		if err := listingTemplate.Execute(cw, l); err != nil {
+			s.onListingError(rw, req, dir, cw, err)
+		}
+	}
+
+	return true
+}
+
+func (s *Server) onListingError(rw http.ResponseWriter, req *http.Request, dir string, cw *committedWriter, err error) {
+	se := s.serveError(req, dir, "", StageCopy, err)
+	se.HeaderWritten = cw.committed
+
+	s.onError(rw, req, http.StatusOK, se)
+}
+
+// listEntries collects accessible directories and logical files in a directory.
+func (s *Server) listEntries(req *http.Request, dir string) []listingEntry {
+	entries := make(map[string]*listingEntry)
+
+	for fn, info := range s.info {
+		if path.Dir(fn) != dir || s.denied(req, s.logicalName(fn)) {
+			continue
+		}
+
+		if info.isDir {
+			entries[fn] = &listingEntry{Name: path.Base(fn), IsDir: true}
+
+			continue
+		}
+
+		name := s.logicalName(fn)
+
+		e, found := entries[name]
+		if !found {
+			e = &listingEntry{Name: path.Base(name)}
+			entries[name] = e
+		}
+
+		if name == fn {
+			size := info.size
+			e.Size = &size
+
+			continue
+		}
+
+		for _, enc := range s.Encodings {
+			if fn == name+enc.FileExt {
+				if e.Encodings == nil {
+					e.Encodings = make(map[string]int)
+				}
+
+				e.Encodings[enc.ContentEncoding] = info.size
+
+				break
+			}
+		}
+	}
+
+	res := make([]listingEntry, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, *e)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].IsDir != res[j].IsDir {
+			return res[i].IsDir
+		}
+
+		return res[i].Name < res[j].Name
+	})
+
+	return res
+}
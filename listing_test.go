@@ -0,0 +1,96 @@
+package statigz_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+	"github.com/vearutop/statigz/brotli"
+)
+
+func TestDirListing(t *testing.T) {
+	s := statigz.FileServer(v, brotli.AddEncoding, statigz.DirListing("/testdata/deeper/"))
+
+	req, err := http.NewRequest(http.MethodGet, "/testdata/deeper/", nil)
+	require.NoError(t, err)
+
+	req.Header.Set("Accept", "application/json")
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+	assert.Equal(t, []string{"Accept-Encoding", "Accept"}, rw.Header().Values("Vary"))
+	assert.JSONEq(t, `{"path":"/testdata/deeper/","entries":[
+		{"name":"openapi.json","encodings":{"gzip":2207}},
+		{"name":"swagger.json","encodings":{"br":2548}}
+	]}`, rw.Body.String())
+
+	req.Header.Set("Accept", "text/html")
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rw.Header().Get("Content-Type"))
+	assert.Contains(t, rw.Body.String(), `<title>Index of /testdata/deeper/</title>`)
+	assert.Contains(t, rw.Body.String(), `<tr><td><a href="swagger.json">swagger.json</a></td><td></td><td>br: 2548 </td></tr>`)
+
+	// Listing is not enabled for other directories.
+	req, err = http.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, err)
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+}
+
+func TestDirListing_all(t *testing.T) {
+	gz := gzipped(t, "abc")
+
+	s := statigz.FileServer(fstest.MapFS{
+		"a.txt":        {Data: []byte("abc")},
+		"a.txt.gz":     {Data: gz},
+		"sub/b.txt":    {Data: []byte("b")},
+		"sub/index.js": {Data: []byte("c")},
+	}, statigz.DirListing())
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, err)
+
+	req.Header.Set("Accept", "application/json")
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.JSONEq(t, `{"path":"/","entries":[
+		{"name":"sub","isDir":true},
+		{"name":"a.txt","size":3,"encodings":{"gzip":`+strconv.Itoa(len(gz))+`}}
+	]}`, rw.Body.String())
+}
+
+func TestDirListing_trailingSlashNever(t *testing.T) {
+	s := statigz.FileServer(fstest.MapFS{
+		"docs/a.json":   {Data: []byte("{}")},
+		"docs/sub/b.js": {Data: []byte("b")},
+	}, statigz.DirListing(), statigz.TrailingSlash(statigz.TrailingSlashNever))
+
+	req, err := http.NewRequest(http.MethodGet, "/docs", nil)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	// Links are relative to parent directory of request path.
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, rw.Body.String(), `<a href="docs/a.json">a.json</a>`)
+	assert.Contains(t, rw.Body.String(), `<a href="docs/sub/">sub/</a>`)
+}
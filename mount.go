@@ -0,0 +1,117 @@
+package statigz
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MountConfig describes a file system served under URL path of Server.
+type MountConfig struct {
+	// Path is a URL path of mounted file system, for example "/docs/".
+	Path string
+
+	// FS is a mounted file system.
+	FS fs.FS
+
+	// Options are applied to mounted file system on top of options inherited from Server.
+	Options []func(server *Server)
+}
+
+// mount is a Server of mounted file system.
+type mount struct {
+	path   string
+	server *Server
+}
+
+// Mount is an option to serve an additional file system under URL path, for example "/docs/".
+//
+// Mounted file system inherits encodings, caching, index, access and error handling options of Server,
+// options of mount are applied on top. Security headers, CORS and method checks of Server are applied
+// to requests of mounted file systems, URL paths in options of mount are relative to mount path.
+func Mount(urlPath string, fsys fs.FS, options ...func(server *Server)) func(server *Server) {
+	return func(server *Server) {
+		server.Mounts = append(server.Mounts, MountConfig{
+			Path:    urlPath,
+			FS:      fsys,
+			Options: options,
+		})
+	}
+}
+
+// inherit copies options of Server to a mounted Server.
+func (s *Server) inherit(m *Server) {
+	// Default handlers are bound to Server, mount installs its own to serve its error pages.
+	if !sameFunc(s.OnError, defaultOnError(nil)) {
+		m.OnError = s.OnError
+	}
+
+	if !sameFunc(s.OnNotFound, defaultOnNotFound(nil)) {
+		m.OnNotFound = s.OnNotFound
+	}
+
+	m.Encodings = append([]Encoding(nil), s.Encodings...)
+	m.EncodeOnInit = s.EncodeOnInit
+	m.EncodedCache = s.EncodedCache
+	m.ReprDigest = s.ReprDigest
+	m.Fingerprint = s.Fingerprint
+	m.RewriteReferences = s.RewriteReferences
+	m.IndexNames = s.IndexNames
+	m.TrailingSlash = s.TrailingSlash
+	m.CleanURLs = s.CleanURLs
+	m.CacheRules = s.CacheRules
+	m.IntegrityHash = s.IntegrityHash
+	m.CaseInsensitive = s.CaseInsensitive
+	m.CaseRedirect = s.CaseRedirect
+	m.Access = s.Access
+}
+
+// sameFunc checks if functions have the same code, closures of the same literal are the same.
+func sameFunc(a, b interface{}) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
+// mountFiles creates servers of mounted file systems, longer paths take precedence.
+func (s *Server) mountFiles() error {
+	for _, mc := range s.Mounts {
+		p := "/" + strings.Trim(mc.Path, "/")
+
+		options := make([]func(server *Server), 0, len(mc.Options)+2)
+		options = append(options, s.inherit)
+		options = append(options, mc.Options...)
+		options = append(options, URLPrefix(s.urlPrefix+p))
+
+		ms, err := NewServer(mc.FS, options...)
+		if err != nil {
+			return fmt.Errorf("mount %s: %w", p, err)
+		}
+
+		s.mounts = append(s.mounts, mount{
+			path:   p,
+			server: ms,
+		})
+	}
+
+	sort.SliceStable(s.mounts, func(i, j int) bool {
+		return len(s.mounts[i].path) > len(s.mounts[j].path)
+	})
+
+	return nil
+}
+
+// mountOf finds mount for canonical URL path, it returns URL path relative to mount.
+func (s *Server) mountOf(p string) (mount, string, bool) {
+	for _, m := range s.mounts {
+		if m.path == "/" {
+			return m, p, true
+		}
+
+		if p == m.path || strings.HasPrefix(p, m.path+"/") {
+			return m, p[len(m.path):], true
+		}
+	}
+
+	return mount{}, "", false
+}
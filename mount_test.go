@@ -0,0 +1,177 @@
+package statigz_test
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+func TestMount(t *testing.T) {
+	docsFS := fstest.MapFS{
+		"index.html": {Data: []byte("docs")},
+		"guide.html": {Data: []byte("guide")},
+	}
+
+	vendorFS := fstest.MapFS{
+		"lib.js.gz": {Data: gzipped(t, "lib")},
+	}
+
+	s := statigz.FileServer(siteFS,
+		statigz.IndexNames("index.html", "index.htm"),
+		statigz.SecurityHeaders(statigz.StrictSecurity),
+		statigz.Mount("/docs/", docsFS, statigz.CleanURLs),
+		statigz.Mount("/vendor", vendorFS),
+		statigz.Mount("/vendor/raw", vendorFS, func(server *statigz.Server) {
+			server.Encodings = nil
+		}),
+	)
+
+	assertResponses(t, s, map[string][2]string{
+		"/":                     {"200", "home"},
+		"/about.html":           {"200", "about"},
+		"/docs/":                {"200", "docs"},
+		"/docs":                 {"301", "/docs/"},
+		"/docs/guide":           {"200", "guide"},
+		"/docs/guide.html":      {"301", "/docs/guide"},
+		"/docs/about.html":      {"404", ""},
+		"/vendor/lib.js":        {"200", "lib"},
+		"/vendor/raw/lib.js":    {"404", ""},
+		"/vendor/raw/lib.js.gz": {"200", string(vendorFS["lib.js.gz"].Data)},
+		"/vendorlib.js":         {"404", ""},
+	})
+
+	for u, expected := range map[string]struct {
+		mount string
+		found bool
+	}{
+		"/about.html":        {"", true},
+		"/docs/guide":        {"/docs", true},
+		"/docs/nonexistent":  {"/docs", false},
+		"/vendor/lib.js":     {"/vendor", true},
+		"/vendor/raw/lib.js": {"/vendor/raw", false},
+	} {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		require.NoError(t, err)
+
+		mount, found := s.FoundMount(req)
+		assert.Equal(t, expected.mount, mount, u)
+		assert.Equal(t, expected.found, found, u)
+	}
+
+	// Mounted files share headers pipeline and negotiation.
+	req, err := http.NewRequest(http.MethodGet, "/vendor/lib.js", nil)
+	require.NoError(t, err)
+
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "gzip", rw.Header().Get("Content-Encoding"))
+	assert.Equal(t, "nosniff", rw.Header().Get("X-Content-Type-Options"))
+
+	req, err = http.NewRequest(http.MethodPost, "/docs/guide", nil)
+	require.NoError(t, err)
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+}
+
+func TestMount_noRootFS(t *testing.T) {
+	s := statigz.FileServer(nil, statigz.URLPrefix("/static/"),
+		statigz.Mount("/ui", fstest.MapFS{"app.js": {Data: []byte("app")}}),
+	)
+
+	assertResponses(t, s, map[string][2]string{
+		"/static/ui/app.js": {"200", "app"},
+		"/static/app.js":    {"404", ""},
+		"/static/":          {"404", ""},
+		"/static/ui":        {"301", "/static/ui/"},
+	})
+}
+
+func TestMount_errorPages(t *testing.T) {
+	s := statigz.FileServer(fstest.MapFS{
+		"index.html": {Data: []byte("home")},
+		"404.html":   {Data: []byte("site not found")},
+	},
+		statigz.ErrorPages(map[int]string{404: "404.html"}),
+		statigz.Mount("/m/", fstest.MapFS{
+			"index.html": {Data: []byte("mount")},
+			"404.html":   {Data: []byte("mount not found")},
+		}, statigz.ErrorPages(map[int]string{404: "404.html"})),
+		statigz.Mount("/plain/", fstest.MapFS{"index.html": {Data: []byte("plain")}}),
+	)
+
+	assertNotFound(t, s, map[string]string{
+		"/missing":       "site not found",
+		"/m/missing":     "mount not found",
+		"/plain/missing": "404 page not found\n",
+	})
+
+	// Explicit handler is inherited.
+	s = statigz.FileServer(siteFS,
+		statigz.OnNotFound(func(rw http.ResponseWriter, r *http.Request) {
+			http.Error(rw, "custom", http.StatusNotFound)
+		}),
+		statigz.Mount("/m/", fstest.MapFS{"index.html": {Data: []byte("mount")}}),
+	)
+
+	assertNotFound(t, s, map[string]string{
+		"/missing":   "custom\n",
+		"/m/missing": "custom\n",
+	})
+}
+
+func assertNotFound(t *testing.T, s http.Handler, bodies map[string]string) {
+	t.Helper()
+
+	for u, body := range bodies {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		assert.Equal(t, http.StatusNotFound, rw.Code, u)
+		assert.Equal(t, body, rw.Body.String(), u)
+	}
+}
+
+func TestMount_URL(t *testing.T) {
+	s := statigz.FileServer(fstest.MapFS{
+		"app.js":         {Data: []byte("app")},
+		"docs/shadow.js": {Data: []byte("shadowed")},
+	},
+		statigz.URLPrefix("/static/"),
+		statigz.Mount("/docs/", fstest.MapFS{"guide.css": {Data: []byte("guide")}}, statigz.Fingerprint),
+	)
+
+	u, err := s.URL("docs/guide.css")
+	require.NoError(t, err)
+	assert.Regexp(t, `^/static/docs/guide\.[0-9a-z]{8}\.css$`, u)
+
+	assertResponses(t, s, map[string][2]string{u: {"200", "guide"}})
+
+	_, err = s.URL("docs/shadow.js")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	sri, err := s.Integrity("/docs/guide.css")
+	require.NoError(t, err)
+	assert.Contains(t, sri, "sha384-")
+
+	assert.Equal(t, map[string]string{
+		"app.js":         "/static/app.js",
+		"docs/guide.css": u,
+	}, s.Manifest())
+}
@@ -0,0 +1,55 @@
+package statigz
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// Overlay is an option to place file systems above file system of Server, first layer is the uppermost.
+//
+// Every layer is indexed, a file in upper layer shadows files with the same logical name in lower layers,
+// including encoded variants, for example "app.js" in os.DirFS("./web") shadows embedded "app.js.br".
+//
+//	statigz.FileServer(embedded, statigz.Overlay(os.DirFS("./web")))
+func Overlay(layers ...fs.FS) func(server *Server) {
+	return func(server *Server) {
+		server.Overlay = append(server.Overlay, layers...)
+	}
+}
+
+// LayerHeader is an option to add response header with index of layer that served file.
+func LayerHeader(name string) func(server *Server) {
+	return func(server *Server) {
+		server.LayerHeader = name
+	}
+}
+
+// hashLayers indexes files of all layers from the uppermost to file system of Server.
+func (s *Server) hashLayers() error {
+	s.layers = append(s.layers[:0], s.Overlay...)
+
+	if s.fs != nil {
+		s.layers = append(s.layers, s.fs)
+	}
+
+	owners := make(map[string]int)
+
+	for i := range s.layers {
+		if err := s.hashDir(i, owners); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodedBase returns file name without encoding extension.
+func (s *Server) encodedBase(fn string) string {
+	for _, enc := range s.Encodings {
+		if strings.HasSuffix(fn, enc.FileExt) {
+			return strings.TrimSuffix(fn, enc.FileExt)
+		}
+	}
+
+	return fn
+}
@@ -0,0 +1,90 @@
+package statigz_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+func TestOverlay(t *testing.T) {
+	base := fstest.MapFS{
+		"app.js.gz":   {Data: gzipped(t, "embedded app")},
+		"style.css":   {Data: []byte("embedded style")},
+		"logo.svg":    {Data: []byte("embedded logo")},
+		"docs/a.txt":  {Data: []byte("embedded a")},
+		"docs/b.txt":  {Data: []byte("embedded b")},
+		"index.html":  {Data: []byte("embedded index")},
+		"readme.html": {Data: []byte("embedded readme")},
+	}
+
+	dev := fstest.MapFS{
+		"app.js":       {Data: []byte("dev app")},
+		"style.css.gz": {Data: gzipped(t, "dev style")},
+		"docs/a.txt":   {Data: []byte("dev a")},
+	}
+
+	top := fstest.MapFS{
+		"docs/a.txt": {Data: []byte("top a")},
+		"index.html": {Data: []byte("top index")},
+	}
+
+	s := statigz.FileServer(base, statigz.Overlay(top, dev), statigz.LayerHeader("X-Layer"))
+
+	for u, expected := range map[string][2]string{
+		"/app.js":      {"dev app", "1"},
+		"/style.css":   {"dev style", "1"},
+		"/logo.svg":    {"embedded logo", "2"},
+		"/docs/a.txt":  {"top a", "0"},
+		"/docs/b.txt":  {"embedded b", "2"},
+		"/":            {"top index", "0"},
+		"/readme.html": {"embedded readme", "2"},
+	} {
+		for _, ae := range []string{"", "gzip"} {
+			req, err := http.NewRequest(http.MethodGet, u, nil)
+			require.NoError(t, err)
+
+			req.Header.Set("Accept-Encoding", ae)
+
+			rw := httptest.NewRecorder()
+			s.ServeHTTP(rw, req)
+
+			assert.Equal(t, http.StatusOK, rw.Code, u)
+			assert.Equal(t, expected[1], rw.Header().Get("X-Layer"), u)
+
+			if rw.Header().Get("Content-Encoding") == "gzip" {
+				assert.Equal(t, string(gzipped(t, expected[0])), rw.Body.String(), u)
+			} else {
+				assert.Equal(t, expected[0], rw.Body.String(), u)
+			}
+		}
+	}
+}
+
+func TestOverlay_encodeOnInit(t *testing.T) {
+	base := fstest.MapFS{
+		"app.js": {Data: []byte("embedded")},
+	}
+
+	dev := fstest.MapFS{
+		"app.js": {Data: []byte(strings.Repeat("console.log(1);\n", 100))},
+	}
+
+	s := statigz.FileServer(base, statigz.Overlay(dev), statigz.EncodeOnInit, statigz.LayerHeader("X-Layer"))
+
+	req, err := http.NewRequest(http.MethodGet, "/app.js", nil)
+	require.NoError(t, err)
+
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, "gzip", rw.Header().Get("Content-Encoding"))
+	assert.Equal(t, "0", rw.Header().Get("X-Layer"))
+}
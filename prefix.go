@@ -0,0 +1,82 @@
+package statigz
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// trimURLPrefix removes URLPrefix from URL path, it returns false if path is outside of URLPrefix.
+func (s *Server) trimURLPrefix(p string) (string, bool) {
+	if s.urlPrefix == "" {
+		return p, true
+	}
+
+	if !strings.HasPrefix(p, s.urlPrefix) {
+		return "", false
+	}
+
+	p = p[len(s.urlPrefix):]
+
+	if p != "" && !strings.HasPrefix(p, "/") {
+		return "", false
+	}
+
+	return p, true
+}
+
+// redirectLocal redirects to a path relative to requested URL path,
+// or to absolute path prefixed with URLPrefix if it is set.
+//
+// Relative redirects are kept for compatibility with std http.FileServer.
+func (s *Server) redirectLocal(rw http.ResponseWriter, req *http.Request, relative, absolute string) {
+	if s.urlPrefix == "" {
+		localRedirect(rw, req, relative)
+
+		return
+	}
+
+	s.redirectAbsolute(rw, req, absolute, http.StatusMovedPermanently)
+}
+
+// redirectAbsolute redirects to URL path within Server, prefixed with mount prefix.
+func (s *Server) redirectAbsolute(rw http.ResponseWriter, req *http.Request, p string, status int) {
+	loc := s.mountPrefix(req) + p
+
+	// Location starting with "//" would refer to another host.
+	if strings.HasPrefix(loc, "//") {
+		loc = "/" + strings.TrimLeft(loc, "/")
+	}
+
+	u := url.URL{Path: loc, RawQuery: req.URL.RawQuery}
+
+	rw.Header().Set("Location", u.String())
+	rw.WriteHeader(status)
+}
+
+// mountPrefix returns URL path prefix that was removed from request path by URLPrefix
+// or by an outer handler, for example http.StripPrefix.
+//
+// Prefix is found by comparing URL path with original request URI, URLPrefix is used if request URI is not available.
+func (s *Server) mountPrefix(req *http.Request) string {
+	if req.RequestURI == "" {
+		return s.urlPrefix
+	}
+
+	u, err := url.ParseRequestURI(req.RequestURI)
+	if err != nil || !strings.HasSuffix(u.Path, req.URL.Path) {
+		return s.urlPrefix
+	}
+
+	// Trailing slash of outer prefix is a leading slash of URL path, for example http.StripPrefix("/", ...).
+	return strings.TrimRight(strings.TrimSuffix(u.Path, req.URL.Path), "/")
+}
+
+// redirectTarget prepends URLPrefix to local redirect target.
+func (s *Server) redirectTarget(target string) string {
+	if strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "//") {
+		return s.urlPrefix + target
+	}
+
+	return target
+}
@@ -0,0 +1,96 @@
+package statigz_test
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+func TestURLPrefix(t *testing.T) {
+	s := statigz.FileServer(siteFS, statigz.URLPrefix("/static/"),
+		statigz.IndexNames("index.html", "index.htm"),
+		statigz.Redirects(statigz.RedirectRule{From: "/home", To: "/"}),
+	)
+
+	assertResponses(t, s, map[string][2]string{
+		"/static/":                 {"200", "home"},
+		"/static/about.html":       {"200", "about"},
+		"/static/docs/":            {"200", "docs"},
+		"/static":                  {"301", "/static/"},
+		"/static/docs":             {"301", "/static/docs/"},
+		"/static/docs/index.htm":   {"301", "/static/docs/"},
+		"/static/index.htm?a=b":    {"301", "/static/?a=b"},
+		"/static//about.html":      {"301", "/static/about.html"},
+		"/static/home":             {"301", "/static/"},
+		"/about.html":              {"404", ""},
+		"/staticabout.html":        {"404", ""},
+		"/static/docs/../docs/":    {"301", "/static/docs/"},
+		"/static/nonexistent.html": {"404", ""},
+	})
+
+	// Escaping mount is not allowed.
+	req, err := http.NewRequest(http.MethodGet, "/static/../about.html", nil)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusBadRequest, rw.Code)
+
+	for u, found := range map[string]bool{
+		"/static/about.html": true,
+		"/static/docs/":      true,
+		"/about.html":        false,
+		"/static/none.html":  false,
+	} {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, found, s.Found(req), u)
+	}
+}
+
+func TestURLPrefix_trailingSlashNever(t *testing.T) {
+	s := statigz.FileServer(siteFS, statigz.URLPrefix("static"),
+		statigz.IndexNames("index.html", "index.htm"),
+		statigz.TrailingSlash(statigz.TrailingSlashNever),
+		statigz.CleanURLs,
+	)
+
+	assertResponses(t, s, map[string][2]string{
+		"/static/docs":            {"200", "docs"},
+		"/static/about":           {"200", "about"},
+		"/static/docs/":           {"301", "/static/docs"},
+		"/static/docs/index.htm":  {"301", "/static/docs"},
+		"/static/about.html":      {"301", "/static/about"},
+		"/static/blog/index.html": {"301", "/static/blog"},
+	})
+}
+
+func TestURLPrefix_URL(t *testing.T) {
+	s := statigz.FileServer(siteFS, statigz.URLPrefix("/static/"), statigz.Fingerprint)
+
+	u, err := s.URL("about.html")
+	require.NoError(t, err)
+	assert.Regexp(t, `^/static/about\.[0-9a-z]{8}\.html$`, u)
+	assert.Equal(t, u, s.Manifest()["about.html"])
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "about", rw.Body.String())
+
+	tpl := template.Must(template.New("").Funcs(statigz.FuncMap(s)).Parse(`{{ asset "about.html" }}`))
+	out := bytes.NewBuffer(nil)
+	require.NoError(t, tpl.Execute(out, nil))
+	assert.Equal(t, u, out.String())
+}
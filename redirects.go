@@ -0,0 +1,295 @@
+package statigz
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RedirectsFile is a name of file in the root of file system with redirect rules.
+const RedirectsFile = "_redirects"
+
+// RedirectRule describes a redirect or a rewrite in the format of Netlify _redirects file.
+//
+// From is a URL path pattern with placeholders and an optional trailing splat,
+// for example "/news/:year/:month/*", it is matched against the whole path, trailing "/" is ignored.
+// To is a target path or URL that can refer placeholders and splat, for example "/blog/:year-:month/:splat".
+type RedirectRule struct {
+	From string
+	To   string
+
+	// Status is http.StatusMovedPermanently (default), http.StatusFound, http.StatusSeeOther,
+	// http.StatusTemporaryRedirect or http.StatusPermanentRedirect for redirects
+	// and http.StatusOK for rewrite that serves target file instead of requested.
+	Status int
+
+	// Force applies rule even if requested file exists, otherwise existing file shadows the rule.
+	Force bool
+}
+
+// ParseRedirects parses rules in the format of Netlify _redirects file.
+//
+// Every line contains a path pattern, a target and an optional status code with "!" suffix to force the rule,
+// empty lines and lines starting with "#" are ignored.
+//
+//	/home              /
+//	/news/:year/*      /blog/:year/:splat  302
+//	/app/*             /index.html         200
+//	/old/*             https://example.com/:splat 301!
+func ParseRedirects(r io.Reader) ([]RedirectRule, error) {
+	var (
+		rules []RedirectRule
+		line  int
+	)
+
+	sc := bufio.NewScanner(r)
+
+	for sc.Scan() {
+		line++
+
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		rule, err := parseRedirect(fields)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func parseRedirect(fields []string) (RedirectRule, error) {
+	if len(fields) < 2 {
+		return RedirectRule{}, errors.New("missing redirect target")
+	}
+
+	if len(fields) > 3 {
+		return RedirectRule{}, fmt.Errorf("unexpected %q", fields[3])
+	}
+
+	rule := RedirectRule{
+		From: fields[0],
+		To:   fields[1],
+	}
+
+	if len(fields) == 3 {
+		status := fields[2]
+
+		if strings.HasSuffix(status, "!") {
+			rule.Force = true
+			status = strings.TrimSuffix(status, "!")
+		}
+
+		code, err := strconv.Atoi(status)
+		if err != nil {
+			return RedirectRule{}, fmt.Errorf("invalid status %q", fields[2])
+		}
+
+		rule.Status = code
+	}
+
+	if err := rule.validate(); err != nil {
+		return RedirectRule{}, err
+	}
+
+	return rule, nil
+}
+
+func (r *RedirectRule) validate() error {
+	if err := validatePattern(r.From); err != nil {
+		return err
+	}
+
+	switch r.Status {
+	case 0:
+		r.Status = http.StatusMovedPermanently
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	case http.StatusOK:
+		if !strings.HasPrefix(r.To, "/") {
+			return fmt.Errorf("rewrite target must be a local path: %q", r.To)
+		}
+	default:
+		return fmt.Errorf("unsupported status %d", r.Status)
+	}
+
+	return nil
+}
+
+// match checks if URL path matches the rule, it returns target with substituted placeholders.
+func (r RedirectRule) match(urlPath string) (string, bool) {
+	params, ok := matchPath(r.From, urlPath)
+	if !ok {
+		return "", false
+	}
+
+	// Longer names are replaced first to avoid partial replacements.
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return len(names[i]) > len(names[j])
+	})
+
+	target := r.To
+	for _, name := range names {
+		target = strings.ReplaceAll(target, ":"+name, params[name])
+	}
+
+	return target, true
+}
+
+// matchPath matches URL path against pattern with placeholders and an optional trailing splat,
+// it returns values of placeholders, splat value is named "splat".
+func matchPath(pattern, urlPath string) (map[string]string, bool) {
+	patternSegments := splitPath(pattern)
+	segments := splitPath(urlPath)
+	params := make(map[string]string)
+	splat := false
+
+	for i, p := range patternSegments {
+		if p == "*" {
+			params["splat"] = strings.Join(segments[i:], "/")
+			splat = true
+
+			break
+		}
+
+		if i >= len(segments) {
+			return nil, false
+		}
+
+		if strings.HasPrefix(p, ":") {
+			params[p[1:]] = segments[i]
+		} else if p != segments[i] {
+			return nil, false
+		}
+	}
+
+	if !splat && len(segments) != len(patternSegments) {
+		return nil, false
+	}
+
+	return params, true
+}
+
+// validatePattern checks path pattern.
+func validatePattern(pattern string) error {
+	if !strings.HasPrefix(pattern, "/") {
+		return fmt.Errorf("path pattern must start with /: %q", pattern)
+	}
+
+	if i := strings.Index(pattern, "*"); i != -1 && i != len(pattern)-1 {
+		return fmt.Errorf("splat must be at the end of path pattern: %q", pattern)
+	}
+
+	return nil
+}
+
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+
+	return strings.Split(p, "/")
+}
+
+// loadRedirects validates rules, reads rules from RedirectsFile and removes it from served files.
+func (s *Server) loadRedirects() error {
+	for i := range s.Redirects {
+		if err := s.Redirects[i].validate(); err != nil {
+			return fmt.Errorf("redirect %s: %w", s.Redirects[i].From, err)
+		}
+	}
+
+	fn := s.fsPrefix + RedirectsFile
+
+	info, found := s.info[fn]
+	if !found || info.isDir {
+		return nil
+	}
+
+	content, err := s.readAll(fn, info, nil)
+	if err != nil {
+		return err
+	}
+
+	rules, err := ParseRedirects(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", fn, err)
+	}
+
+	delete(s.info, fn)
+
+	s.Redirects = append(rules, s.Redirects...)
+
+	return nil
+}
+
+// matchRedirect finds the first rule matching the request.
+func (s *Server) matchRedirect(req *http.Request) (RedirectRule, string, bool) {
+	for _, r := range s.Redirects {
+		target, ok := r.match(req.URL.Path)
+		if !ok {
+			continue
+		}
+
+		if !r.Force {
+			fn, _ := s.resolve(s.fsPrefix + strings.TrimPrefix(req.URL.Path, "/"))
+			if _, found := s.primaryInfo(fn); found {
+				continue
+			}
+		}
+
+		if req.URL.RawQuery != "" && !strings.Contains(target, "?") {
+			target += "?" + req.URL.RawQuery
+		}
+
+		return r, target, true
+	}
+
+	return RedirectRule{}, "", false
+}
+
+// rewrite returns a shallow copy of request with URL replaced by target path with optional query.
+func rewrite(req *http.Request, target string) *http.Request {
+	if i := strings.Index(target, "?"); i != -1 {
+		r := withPath(req, target[:i])
+		r.URL.RawQuery = target[i+1:]
+
+		return r
+	}
+
+	return withPath(req, target)
+}
+
+// withPath returns a shallow copy of request with URL path replaced.
+func withPath(req *http.Request, p string) *http.Request {
+	r := new(http.Request)
+	*r = *req
+
+	u := *req.URL
+	u.Path = p
+	u.RawPath = ""
+	r.URL = &u
+
+	return r
+}
@@ -0,0 +1,104 @@
+package statigz_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+func TestParseRedirects(t *testing.T) {
+	rules, err := statigz.ParseRedirects(strings.NewReader(`
+# Comment.
+/home              /
+/news/:year/*      /blog/:year/:splat  302
+/app/*             /index.html         200
+/old/*             https://example.com/:splat 301!
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []statigz.RedirectRule{
+		{From: "/home", To: "/", Status: http.StatusMovedPermanently},
+		{From: "/news/:year/*", To: "/blog/:year/:splat", Status: http.StatusFound},
+		{From: "/app/*", To: "/index.html", Status: http.StatusOK},
+		{From: "/old/*", To: "https://example.com/:splat", Status: http.StatusMovedPermanently, Force: true},
+	}, rules)
+
+	for src, msg := range map[string]string{
+		"/a":                        "line 1: missing redirect target",
+		"\n/a /b 301 Country=us":    `line 2: unexpected "Country=us"`,
+		"/a /b 30x":                 `line 1: invalid status "30x"`,
+		"/a /b 404":                 "line 1: unsupported status 404",
+		"a /b":                      `line 1: path pattern must start with /: "a"`,
+		"/a/*/b /b":                 `line 1: splat must be at the end of path pattern: "/a/*/b"`,
+		"# Ok.\n\n/a https://b 200": `line 3: rewrite target must be a local path: "https://b"`,
+	} {
+		_, err := statigz.ParseRedirects(strings.NewReader(src))
+		assert.EqualError(t, err, msg, src)
+	}
+}
+
+func TestServer_ServeHTTP_redirects(t *testing.T) {
+	s := statigz.FileServer(fstest.MapFS{
+		"_redirects": {Data: []byte(`
+/news/:year/:month/*  /blog/:year-:month/:splat  307
+/about.html           /team.html                 302!
+/app/*                /index.html                200
+/docs/*               /manual/:splat             200
+`)},
+		"index.html":        {Data: []byte("index")},
+		"about.html":        {Data: []byte("about")},
+		"team.html":         {Data: []byte("team")},
+		"manual/intro.html": {Data: []byte("intro")},
+		"app/existing.txt":  {Data: []byte("existing")},
+	}, statigz.Redirects(statigz.RedirectRule{From: "/legacy", To: "/"}))
+
+	for u, resp := range map[string][2]string{
+		"/news/2023/07/hello/world?a=1": {"307", "/blog/2023-07/hello/world?a=1"},
+		"/news/2023":                    {"404", ""},
+		"/about.html":                   {"302", "/team.html"},
+		"/legacy":                       {"301", "/"},
+		"/app/users/1":                  {"200", "index"},
+		"/app/existing.txt":             {"200", "existing"},
+		"/docs/intro.html":              {"200", "intro"},
+		"/docs/missing.html":            {"404", ""},
+		"/_redirects":                   {"404", ""},
+	} {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		switch resp[0] {
+		case "200":
+			assert.Equal(t, http.StatusOK, rw.Code, u)
+			assert.Equal(t, resp[1], rw.Body.String(), u)
+			assert.True(t, s.Found(req), u)
+		case "404":
+			assert.Equal(t, http.StatusNotFound, rw.Code, u)
+			assert.False(t, s.Found(req), u)
+		default:
+			assert.Equal(t, resp[0], strconv.Itoa(rw.Code), u)
+			assert.Equal(t, resp[1], rw.Header().Get("Location"), u)
+			assert.True(t, s.Found(req), u)
+		}
+	}
+}
+
+func TestFileServer_badRedirects(t *testing.T) {
+	defer func() {
+		err, ok := recover().(error)
+		require.True(t, ok)
+		assert.EqualError(t, err, "load redirects: parse _redirects: line 2: missing redirect target")
+	}()
+
+	statigz.FileServer(fstest.MapFS{
+		"_redirects": {Data: []byte("/a /b\n/c")},
+	})
+}
@@ -0,0 +1,296 @@
+package statigz
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	htmlSrcRef    = regexp.MustCompile(`(?i)\ssrc\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	htmlHrefRef   = regexp.MustCompile(`(?i)\shref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	htmlRelAttr   = regexp.MustCompile(`(?i)\srel\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	htmlLinkTag   = regexp.MustCompile(`(?i)<link\s[^>]*>`)
+	htmlSrcsetRef = regexp.MustCompile(`(?i)\ssrcset\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	cssURLRef     = regexp.MustCompile(`(?i)url\(\s*(?:"([^"]*)"|'([^']*)'|([^\s"')]+))\s*\)`)
+	cssImportRef  = regexp.MustCompile(`(?i)@import\s+(?:"([^"]*)"|'([^']*)')`)
+)
+
+// Rewrite states of a file.
+const (
+	rewritePending = iota + 1
+	rewriteDone
+)
+
+// referenceRewriter replaces references to files in HTML and CSS with fingerprinted URLs.
+//
+// Only asset references are rewritten: src, srcset, href of stylesheet, preload and icon links, url() and @import in CSS.
+// Documents (HTML) are not referred by fingerprint, so that navigation links are not cached as immutable.
+// Referenced CSS files are rewritten first, so that fingerprints reflect rewritten content.
+// References that form a cycle, for example stylesheets importing each other, are kept as is.
+type referenceRewriter struct {
+	s     *Server
+	state map[string]int
+}
+
+func (s *Server) rewriteReferences() error {
+	rr := referenceRewriter{
+		s:     s,
+		state: make(map[string]int),
+	}
+
+	for name := range s.logicalNames() {
+		if err := rr.rewrite(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func rewritable(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".html", ".htm", ".css":
+		return true
+	default:
+		return false
+	}
+}
+
+func (rr *referenceRewriter) rewrite(name string) error {
+	if !rewritable(name) || rr.state[name] != 0 {
+		return nil
+	}
+
+	rr.state[name] = rewritePending
+	defer func() { rr.state[name] = rewriteDone }()
+
+	content, err := rr.s.identityContent(name)
+	if err != nil {
+		return err
+	}
+
+	dir := path.Dir(name)
+	ref := func(ref string) string {
+		return rr.ref(dir, ref)
+	}
+
+	res := replaceRefs(cssURLRef, content, ref)
+	res = replaceRefs(cssImportRef, res, ref)
+
+	if strings.ToLower(path.Ext(name)) != ".css" {
+		res = replaceRefs(htmlSrcRef, res, ref)
+		res = htmlLinkTag.ReplaceAllFunc(res, func(tag []byte) []byte {
+			if !assetLink(tag) {
+				return tag
+			}
+
+			return replaceRefs(htmlHrefRef, tag, ref)
+		})
+		res = replaceRefs(htmlSrcsetRef, res, func(srcset string) string {
+			candidates := strings.Split(srcset, ",")
+
+			for i, c := range candidates {
+				fields := strings.Fields(c)
+				if len(fields) == 0 {
+					continue
+				}
+
+				candidates[i] = strings.Replace(c, fields[0], ref(fields[0]), 1)
+			}
+
+			return strings.Join(candidates, ",")
+		})
+	}
+
+	if bytes.Equal(content, res) {
+		return nil
+	}
+
+	return rr.s.replaceContent(name, res)
+}
+
+// assetLink checks if link tag refers to an asset, for example a stylesheet, a preload or an icon.
+func assetLink(tag []byte) bool {
+	m := htmlRelAttr.FindSubmatch(tag)
+	if m == nil {
+		return false
+	}
+
+	for _, rel := range strings.Fields(strings.ToLower(string(bytes.Join(m[1:], nil)))) {
+		switch rel {
+		case "stylesheet", "preload", "modulepreload", "prefetch", "icon", "apple-touch-icon", "mask-icon", "manifest":
+			return true
+		}
+	}
+
+	return false
+}
+
+// ref returns fingerprinted reference to a file, or original reference if it does not point to a served file.
+func (rr *referenceRewriter) ref(dir, ref string) string {
+	p, suffix := ref, ""
+
+	if i := strings.IndexAny(ref, "?#"); i != -1 {
+		p, suffix = ref[:i], ref[i:]
+	}
+
+	if p == "" || strings.HasPrefix(p, "//") || strings.Contains(p, ":") {
+		return ref
+	}
+
+	target := path.Join(dir, p)
+	if strings.HasPrefix(p, "/") {
+		local, ok := rr.s.trimURLPrefix(p)
+		if !ok {
+			return ref
+		}
+
+		target = rr.s.fsPrefix + strings.TrimPrefix(local, "/")
+	}
+
+	// Documents are not fingerprinted in references.
+	if ext := strings.ToLower(path.Ext(target)); ext == ".html" || ext == ".htm" {
+		return ref
+	}
+
+	// Rewriting referenced file first as it may change its fingerprint.
+	if err := rr.rewrite(target); err != nil {
+		return ref
+	}
+
+	// File that is being rewritten is a part of reference cycle, its fingerprint is not final yet.
+	if rr.state[target] == rewritePending {
+		return ref
+	}
+
+	alias, found := rr.s.fingerprints[target]
+	if !found {
+		return ref
+	}
+
+	return p[:strings.LastIndex(p, "/")+1] + path.Base(alias) + suffix
+}
+
+// replaceRefs replaces first matched non-empty group in every regular expression match.
+func replaceRefs(re *regexp.Regexp, src []byte, replace func(string) string) []byte {
+	var (
+		res  = bytes.NewBuffer(nil)
+		last = 0
+	)
+
+	for _, m := range re.FindAllSubmatchIndex(src, -1) {
+		for g := 2; g < len(m); g += 2 {
+			if m[g] == -1 {
+				continue
+			}
+
+			res.Write(src[last:m[g]])
+			res.WriteString(replace(string(src[m[g]:m[g+1]])))
+			last = m[g+1]
+
+			break
+		}
+	}
+
+	res.Write(src[last:])
+
+	return res.Bytes()
+}
+
+// identityContent reads identity content of a file, decoding it if necessary.
+func (s *Server) identityContent(name string) ([]byte, error) {
+	if info, found := s.info[name]; found {
+		return s.readAll(name, info, nil)
+	}
+
+	for _, enc := range s.Encodings {
+		info, found := s.info[name+enc.FileExt]
+		if !found || enc.Decoder == nil || info.isDir {
+			continue
+		}
+
+		return s.readAll(name+enc.FileExt, info, enc.Decoder)
+	}
+
+	return nil, fmt.Errorf("file not found: %s", name)
+}
+
+// readAll reads whole content of a file, with fs.ReadFileFS if available, and decodes it with optional decoder.
+func (s *Server) readAll(fn string, info fileInfo, decoder func(r io.Reader) (io.Reader, error)) ([]byte, error) {
+	content := info.content
+
+	if content == nil {
+		var err error
+
+		if content, err = fs.ReadFile(s.layers[info.layer], fn); err != nil {
+			return nil, err
+		}
+	}
+
+	if decoder == nil {
+		return content, nil
+	}
+
+	r, err := decoder(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", fn, err)
+	}
+
+	return io.ReadAll(r)
+}
+
+// replaceContent replaces identity content of a file, encodes it again and updates fingerprint.
+func (s *Server) replaceContent(name string, content []byte) error {
+	h := fnv.New64()
+	_, _ = h.Write(content)
+
+	primary, _ := s.primaryInfo(name)
+
+	info := fileInfo{
+		hash:    strconv.FormatUint(h.Sum64(), 36),
+		size:    len(content),
+		content: content,
+		digests: s.digestOf(content),
+		layer:   primary.layer,
+	}
+
+	s.info[name] = info
+	delete(s.identity, name)
+
+	for _, enc := range s.Encodings {
+		if _, found := s.info[name+enc.FileExt]; !found {
+			continue
+		}
+
+		// Stale encoded content can not be served.
+		delete(s.info, name+enc.FileExt)
+
+		if enc.Encoder == nil {
+			continue
+		}
+
+		b, err := enc.Encoder(bytes.NewReader(content))
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", name, err)
+		}
+
+		s.info[name+enc.FileExt] = fileInfo{
+			hash:    info.hash + enc.FileExt,
+			size:    len(b),
+			content: b[0:len(b):len(b)],
+			digests: s.reprDigests(b),
+			layer:   info.layer,
+		}
+	}
+
+	s.fingerprint(name)
+
+	return nil
+}
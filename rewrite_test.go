@@ -0,0 +1,206 @@
+package statigz_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b := bytes.NewBuffer(nil)
+	w := gzip.NewWriter(b)
+
+	_, err := w.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return b.Bytes()
+}
+
+func TestRewriteReferences(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js":        {Data: []byte(`console.log("hello")`)},
+		"img/a.png":     {Data: []byte("a")},
+		"img/b.png":     {Data: []byte("b")},
+		"img/logo.png":  {Data: []byte("logo")},
+		"css/reset.css": {Data: []byte(`body { margin: 0 }`)},
+		"css/style.css": {Data: []byte(`@import "reset.css"; h1 { background: url('../img/logo.png') }`)},
+		"index.html.gz": {Data: gzipped(t, `<link href="/css/style.css?v=1" rel="stylesheet"><script src=app.js></script>`)},
+		"about/ab.html": {Data: []byte(`<img srcset="../img/a.png 1x, ../img/b.png 2x" src="https://example.com/app.js"><a href="#top">Top</a>`)},
+	}
+
+	s := statigz.FileServer(fsys, statigz.RewriteReferences)
+
+	get := func(name string) string {
+		u, err := s.URL(name)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		require.NoError(t, err)
+
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		require.Equal(t, http.StatusOK, rw.Code, u)
+
+		if rw.Header().Get("Content-Encoding") != "gzip" {
+			return rw.Body.String()
+		}
+
+		r, err := gzip.NewReader(rw.Body)
+		require.NoError(t, err)
+
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		return string(b)
+	}
+
+	url := func(name string) string {
+		u, err := s.URL(name)
+		require.NoError(t, err)
+
+		return u
+	}
+
+	assert.Equal(t, `<link href="`+url("css/style.css")+`?v=1" rel="stylesheet"><script src=`+url("app.js")[1:]+`></script>`,
+		get("index.html"))
+	assert.Equal(t, `@import "`+url("css/reset.css")[5:]+`"; h1 { background: url('../img/`+url("img/logo.png")[5:]+`') }`,
+		get("css/style.css"))
+	assert.Equal(t, `<img srcset="../img/`+url("img/a.png")[5:]+` 1x, ../img/`+url("img/b.png")[5:]+` 2x" src="https://example.com/app.js"><a href="#top">Top</a>`,
+		get("about/ab.html"))
+	assert.Equal(t, `body { margin: 0 }`, get("css/reset.css"))
+}
+
+func TestRewriteReferences_cycle(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.css":    {Data: []byte(`@import "b.css"; @import "a.css"; h1 { background: url("logo.png") }`)},
+		"b.css":    {Data: []byte(`@import "a.css"; h2 { background: url("logo.png") }`)},
+		"c.css":    {Data: []byte(`@import "a.css"; @import "b.css";`)},
+		"logo.png": {Data: []byte("logo")},
+	}
+
+	s := statigz.FileServer(fsys, statigz.RewriteReferences)
+	ref := regexp.MustCompile(`(?:url\(|import )"([^"]+)"`)
+
+	for name := range fsys {
+		u, err := s.URL(name)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+		require.Equal(t, http.StatusOK, rw.Code, u)
+
+		// Every reference must point to a served file.
+		for _, m := range ref.FindAllStringSubmatch(rw.Body.String(), -1) {
+			req, err := http.NewRequest(http.MethodGet, "/"+m[1], nil)
+			require.NoError(t, err)
+
+			assert.True(t, s.Found(req), name+": "+m[1])
+		}
+
+		if name == "c.css" {
+			a, err := s.URL("a.css")
+			require.NoError(t, err)
+
+			// References outside of cycle are rewritten.
+			assert.Contains(t, rw.Body.String(), `@import "`+a[1:]+`"`)
+		}
+	}
+}
+
+func TestRewriteReferences_documents(t *testing.T) {
+	page := `<link rel="canonical" href="about.html"><link rel="alternate" href="feed.xml">` +
+		`<link rel="shortcut icon" href="favicon.png"><link href="style.css" rel=stylesheet>` +
+		`<a href="about.html">About</a><a href="logo.png">Logo</a><iframe src="about.html"></iframe>`
+
+	fsys := fstest.MapFS{
+		"index.html":  {Data: []byte(page)},
+		"about.html":  {Data: []byte(`<a href="index.html">Home</a>`)},
+		"favicon.png": {Data: []byte("icon")},
+		"logo.png":    {Data: []byte("logo")},
+		"feed.xml":    {Data: []byte("<rss/>")},
+		"style.css":   {Data: []byte("body { margin: 0 }")},
+	}
+
+	s := statigz.FileServer(fsys, statigz.RewriteReferences)
+
+	url := func(name string) string {
+		u, err := s.URL(name)
+		require.NoError(t, err)
+
+		return u[1:]
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	// Navigation links and documents keep their names, asset links are fingerprinted.
+	assert.Equal(t, `<link rel="canonical" href="about.html"><link rel="alternate" href="feed.xml">`+
+		`<link rel="shortcut icon" href="`+url("favicon.png")+`"><link href="`+url("style.css")+`" rel=stylesheet>`+
+		`<a href="about.html">About</a><a href="logo.png">Logo</a><iframe src="about.html"></iframe>`, rw.Body.String())
+
+	req, err = http.NewRequest(http.MethodGet, "/about.html", nil)
+	require.NoError(t, err)
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, `<a href="index.html">Home</a>`, rw.Body.String())
+	assert.Empty(t, rw.Header().Get("Cache-Control"))
+}
+
+// readFileFS counts whole file reads.
+type readFileFS struct {
+	fstest.MapFS
+	reads map[string]int
+}
+
+func (r readFileFS) ReadFile(name string) ([]byte, error) {
+	r.reads[name]++
+
+	return r.MapFS.ReadFile(name)
+}
+
+func TestRewriteReferences_readFile(t *testing.T) {
+	fsys := readFileFS{
+		MapFS: fstest.MapFS{
+			"index.html":    {Data: []byte(`<script src="app.js"></script>`)},
+			"style.css.gz":  {Data: gzipped(t, `body { margin: 0 }`)},
+			"app.js":        {Data: []byte("app")},
+			"_redirects":    {Data: []byte("/home / 301")},
+			"_headers":      {Data: []byte("/*\n  X-Frame-Options: DENY")},
+			"not-read.json": {Data: []byte("{}")},
+		},
+		reads: make(map[string]int),
+	}
+
+	statigz.FileServer(fsys, statigz.RewriteReferences)
+
+	// Whole files are read with fs.ReadFileFS, other files are streamed.
+	assert.Equal(t, map[string]int{
+		"index.html":   1,
+		"style.css.gz": 1,
+		"_redirects":   1,
+		"_headers":     1,
+	}, fsys.reads)
+}
@@ -0,0 +1,106 @@
+package statigz
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// SecurityPreset contains values of security response headers, empty value omits the header.
+type SecurityPreset struct {
+	// ContentTypeOptions is a value of X-Content-Type-Options header.
+	ContentTypeOptions string
+
+	// ReferrerPolicy is a value of Referrer-Policy header.
+	ReferrerPolicy string
+
+	// CrossOriginResourcePolicy is a value of Cross-Origin-Resource-Policy header.
+	CrossOriginResourcePolicy string
+
+	// CrossOriginOpenerPolicy is a value of Cross-Origin-Opener-Policy header.
+	CrossOriginOpenerPolicy string
+
+	// CrossOriginEmbedderPolicy is a value of Cross-Origin-Embedder-Policy header.
+	CrossOriginEmbedderPolicy string
+}
+
+var (
+	// StrictSecurity disallows cross-origin usage of resources and does not send referrer.
+	StrictSecurity = SecurityPreset{
+		ContentTypeOptions:        "nosniff",
+		ReferrerPolicy:            "no-referrer",
+		CrossOriginResourcePolicy: "same-origin",
+		CrossOriginOpenerPolicy:   "same-origin",
+	}
+
+	// RelaxedSecurity allows cross-origin usage of resources, for example for assets served to other sites.
+	RelaxedSecurity = SecurityPreset{
+		ContentTypeOptions:        "nosniff",
+		ReferrerPolicy:            "strict-origin-when-cross-origin",
+		CrossOriginResourcePolicy: "cross-origin",
+	}
+)
+
+// CrossOriginIsolated returns a copy of preset with COOP and COEP headers that enable
+// cross-origin isolation, which is required for SharedArrayBuffer and multithreaded wasm.
+func (p SecurityPreset) CrossOriginIsolated() SecurityPreset {
+	p.CrossOriginOpenerPolicy = "same-origin"
+	p.CrossOriginEmbedderPolicy = "require-corp"
+
+	return p
+}
+
+func (p SecurityPreset) set(h http.Header) {
+	for k, v := range map[string]string{
+		"X-Content-Type-Options":       p.ContentTypeOptions,
+		"Referrer-Policy":              p.ReferrerPolicy,
+		"Cross-Origin-Resource-Policy": p.CrossOriginResourcePolicy,
+		"Cross-Origin-Opener-Policy":   p.CrossOriginOpenerPolicy,
+		"Cross-Origin-Embedder-Policy": p.CrossOriginEmbedderPolicy,
+	} {
+		if v != "" {
+			h.Set(k, v)
+		}
+	}
+}
+
+// SecurityRule applies security preset to URL paths matching a pattern.
+//
+// Path is a URL path pattern with placeholders and an optional trailing splat, for example "/fonts/*".
+type SecurityRule struct {
+	Path   string
+	Preset SecurityPreset
+}
+
+// SecurityHeaders is an option to add security headers of a preset to all responses,
+// overrides define other presets for matching URL paths.
+//
+//	statigz.SecurityHeaders(statigz.StrictSecurity,
+//		statigz.SecurityRule{Path: "/fonts/*", Preset: statigz.RelaxedSecurity},
+//		statigz.SecurityRule{Path: "/app/*", Preset: statigz.StrictSecurity.CrossOriginIsolated()},
+//	)
+func SecurityHeaders(preset SecurityPreset, overrides ...SecurityRule) func(server *Server) {
+	return func(server *Server) {
+		server.Security = append(overrides, SecurityRule{Path: "/*", Preset: preset})
+	}
+}
+
+func (s *Server) validateSecurity() error {
+	for _, r := range s.Security {
+		if err := validatePattern(r.Path); err != nil {
+			return fmt.Errorf("security %s: %w", r.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// setSecurityHeaders adds headers of the first security rule matching URL path.
+func (s *Server) setSecurityHeaders(h http.Header, urlPath string) {
+	for _, r := range s.Security {
+		if _, ok := matchPath(r.Path, urlPath); ok {
+			r.Preset.set(h)
+
+			return
+		}
+	}
+}
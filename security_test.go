@@ -0,0 +1,58 @@
+package statigz_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	s := statigz.FileServer(v, statigz.FSPrefix("testdata"), statigz.SecurityHeaders(statigz.StrictSecurity,
+		statigz.SecurityRule{Path: "/deeper/*", Preset: statigz.RelaxedSecurity},
+		statigz.SecurityRule{Path: "/", Preset: statigz.StrictSecurity.CrossOriginIsolated()},
+	))
+
+	for u, expected := range map[string]http.Header{
+		"/swagger.json": {
+			"X-Content-Type-Options":       {"nosniff"},
+			"Referrer-Policy":              {"no-referrer"},
+			"Cross-Origin-Resource-Policy": {"same-origin"},
+			"Cross-Origin-Opener-Policy":   {"same-origin"},
+		},
+		"/nonexistent": {
+			"X-Content-Type-Options":       {"nosniff"},
+			"Referrer-Policy":              {"no-referrer"},
+			"Cross-Origin-Resource-Policy": {"same-origin"},
+			"Cross-Origin-Opener-Policy":   {"same-origin"},
+		},
+		"/deeper/openapi.json": {
+			"X-Content-Type-Options":       {"nosniff"},
+			"Referrer-Policy":              {"strict-origin-when-cross-origin"},
+			"Cross-Origin-Resource-Policy": {"cross-origin"},
+		},
+		"/": {
+			"X-Content-Type-Options":       {"nosniff"},
+			"Referrer-Policy":              {"no-referrer"},
+			"Cross-Origin-Resource-Policy": {"same-origin"},
+			"Cross-Origin-Opener-Policy":   {"same-origin"},
+			"Cross-Origin-Embedder-Policy": {"require-corp"},
+		},
+	} {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		require.NoError(t, err)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		for _, h := range []string{
+			"X-Content-Type-Options", "Referrer-Policy", "Cross-Origin-Resource-Policy",
+			"Cross-Origin-Opener-Policy", "Cross-Origin-Embedder-Policy",
+		} {
+			assert.Equal(t, expected.Get(h), rw.Header().Get(h), u+" "+h)
+		}
+	}
+}
@@ -4,6 +4,7 @@ package statigz
 import (
 	"bytes"
 	"compress/gzip"
+	"fmt"
 	"hash/fnv"
 	"io"
 	"io/fs"
@@ -32,7 +33,8 @@ import (
 // Behavior is similar to http://nginx.org/en/docs/http/ngx_http_gzip_static_module.html and
 // https://github.com/lpar/gzipped, except compressed data can be decompressed for an incapable agent.
 type Server struct {
-	// OnError controls error handling during Serve.
+	// OnError controls error handling during Serve, error is a *ServeError.
+	// Error status should not be written if CanWriteStatus returns false, as response is already started.
 	OnError func(rw http.ResponseWriter, r *http.Request, err error)
 
 	// OnNotFound controls handling of not found files.
@@ -48,6 +50,91 @@ type Server struct {
 	// of large embeddings, use with caution.
 	EncodeOnInit bool
 
+	// EncodedCache shares contents encoded with EncodeOnInit between servers.
+	EncodedCache *EncodedCache
+
+	// ReprDigest enables Repr-Digest and Content-Digest response headers (RFC 9530)
+	// with sha-256 and sha-512 digests of served representation.
+	// Digests are computed once on Server init.
+	ReprDigest bool
+
+	// Fingerprint enables fingerprinted aliases of files, e.g. "app.1bp69hxb.js" for "app.js".
+	// Aliases are served with immutable Cache-Control, use URL or Manifest to obtain them.
+	Fingerprint bool
+
+	// RewriteReferences enables rewriting of references to files in HTML (src, href, srcset)
+	// and CSS (url(), @import) to fingerprinted URLs on Server init, rewritten files are encoded again.
+	// It requires Fingerprint.
+	RewriteReferences bool
+
+	// SPAFallback is a name of a document (usually "index.html") to serve for unknown paths
+	// that look like client-side routes of a single-page application: paths without file
+	// extension requested with "Accept: text/html".
+	SPAFallback string
+
+	// SPAExclude lists URL path prefixes that are not served with SPAFallback, default "/api/".
+	SPAExclude []string
+
+	// IndexNames lists names of directory index documents in order of preference, default "index.html".
+	IndexNames []string
+
+	// TrailingSlash defines redirects of directory paths, default TrailingSlashAlways.
+	TrailingSlash TrailingSlashPolicy
+
+	// CleanURLs enables serving "about.html" for "/about" path and redirecting "/about.html" to "/about".
+	CleanURLs bool
+
+	// ErrorPages maps response status codes to names of files that are served as error pages,
+	// for example {404: "404.html", 500: "500.html"}.
+	// Error pages are used by default OnNotFound and OnError.
+	ErrorPages map[int]string
+
+	// Redirects are applied before serving files, rules from RedirectsFile ("_redirects")
+	// in the root of file system are prepended on Server init.
+	Redirects []RedirectRule
+
+	// Headers are added to responses of matching URL paths, rules from HeadersFile ("_headers")
+	// in the root of file system are prepended on Server init.
+	Headers []HeaderRule
+
+	// Security defines security headers for all responses, first rule matching URL path is applied.
+	Security []SecurityRule
+
+	// CORS enables Cross-Origin Resource Sharing headers and preflight requests.
+	CORS *CORSConfig
+
+	// Access controls which files can be served, dotfiles are denied by default.
+	Access AccessPolicy
+
+	// CaseInsensitive enables case-insensitive lookup of files, for example "/Images/Logo.PNG" for "images/logo.png".
+	CaseInsensitive bool
+
+	// CaseRedirect enables redirects of case-insensitive matches to URL path with canonical case,
+	// otherwise file is served directly.
+	CaseRedirect bool
+
+	// Overlay lists file systems placed above file system of Server, first one is the uppermost.
+	// Upper layers shadow lower layers per logical file, for example "app.js" in upper layer
+	// shadows "app.js" and "app.js.br" in lower layers.
+	Overlay []fs.FS
+
+	// LayerHeader is a name of response header with index of layer that served file, for example "X-Layer".
+	// Overlay layers are indexed from 0, file system of Server is the last layer.
+	LayerHeader string
+
+	// Mounts are file systems served under URL paths with their own options, see Mount.
+	Mounts []MountConfig
+
+	// DirListing lists URL path prefixes where directories without index are listed, "/" enables all directories.
+	// Listing is rendered as HTML, or as JSON for "Accept: application/json".
+	DirListing []string
+
+	// CacheRules define Cache-Control header value for served files, first matching rule is applied.
+	CacheRules []CacheRule
+
+	// IntegrityHash is a hash algorithm for Integrity: "sha256", "sha384" (default) or "sha512".
+	IntegrityHash string
+
 	// FSPrefix is a path prefix shat should be ignored.
 	// It is prepended to the incoming HTTP path.
 	// This can help to keep static assets in a subdirectory, e.g.
@@ -55,9 +142,23 @@ type Server struct {
 	// But access files from HTTP without "/static/" prefix in the path.
 	FSPrefix string
 
-	info     map[string]fileInfo
-	fs       fs.ReadDirFS
-	fsPrefix string
+	// URLPrefix is a URL path prefix where Server is mounted, e.g. "/static/".
+	// It is removed from the incoming HTTP path instead of using http.StripPrefix,
+	// requests outside of prefix are not found.
+	// Redirects are absolute and include prefix, URL, Manifest and FuncMap provide prefixed URLs.
+	// URL paths in other options and rules are relative to prefix.
+	URLPrefix string
+
+	info         map[string]fileInfo
+	folded       map[string]string
+	identity     map[string]digests
+	fingerprints map[string]string
+	aliases      map[string]string
+	fs           fs.FS
+	layers       []fs.FS
+	fsPrefix     string
+	urlPrefix    string
+	mounts       []mount
 }
 
 const (
@@ -71,49 +172,131 @@ const (
 // SkipCompressionExt lists file extensions of data that is already compressed.
 var SkipCompressionExt = []string{".gz", ".br", ".gif", ".jpg", ".png", ".webp"}
 
-// FileServer creates an instance of Server from file system.
+// FileServer creates an instance of Server from file system, it panics on error.
 //
 // This function indexes provided file system to optimize further serving,
 // so it is not recommended running it in the loop (for example for each request).
 //
-// Typically, file system would be an embed.FS.
+// Typically, file system would be an embed.FS, but any fs.FS is supported, for example a result of fs.Sub,
+// os.DirFS or zip.Reader. File system can be nil if all files are served with Mount.
 //
 //	//go:embed *.png *.br
 //	var FS embed.FS
 //
 // Brotli support is optionally available with brotli.AddEncoding.
-func FileServer(fs fs.ReadDirFS, options ...func(server *Server)) *Server {
-	s := Server{
-		fs:   fs,
-		info: make(map[string]fileInfo),
-		OnError: func(rw http.ResponseWriter, r *http.Request, err error) {
-			http.Error(rw, "Internal Server Error", http.StatusInternalServerError)
-		},
-		OnNotFound: http.NotFound,
-		Encodings:  []Encoding{GzipEncoding()},
+//
+// Please use NewServer for file systems that can fail, for example os.DirFS.
+func FileServer(fs fs.FS, options ...func(server *Server)) *Server {
+	s, err := NewServer(fs, options...)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// NewServer creates an instance of Server from file system, see FileServer.
+//
+// It returns error if options are invalid or file system can not be indexed, error names failing stage and file.
+func NewServer(fsys fs.FS, options ...func(server *Server)) (*Server, error) {
+	s := &Server{
+		fs:            fsys,
+		info:          make(map[string]fileInfo),
+		identity:      make(map[string]digests),
+		Encodings:     []Encoding{GzipEncoding()},
+		IntegrityHash: "sha384",
+		SPAExclude:    []string{"/api/"},
+		IndexNames:    []string{"index.html"},
 	}
 
+	s.OnError = defaultOnError(s)
+	s.OnNotFound = defaultOnNotFound(s)
+
 	for _, o := range options {
-		o(&s)
+		o(s)
+	}
+
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("validate options: %w", err)
 	}
 
 	if s.FSPrefix != "" {
 		s.fsPrefix = strings.Trim(s.FSPrefix, "/") + "/"
 	}
 
-	// Reading from "." is not expected to fail.
-	if err := s.hashDir("."); err != nil {
-		panic(err)
+	if p := strings.Trim(s.URLPrefix, "/"); p != "" {
+		s.urlPrefix = "/" + p
 	}
 
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// defaultOnError serves error page of Server or plain text error.
+func defaultOnError(s *Server) func(rw http.ResponseWriter, r *http.Request, err error) {
+	return func(rw http.ResponseWriter, r *http.Request, err error) {
+		// Response is incomplete, but it can not be replaced with error.
+		if !CanWriteStatus(err) {
+			return
+		}
+
+		if !s.serveErrorPage(rw, r, http.StatusInternalServerError) {
+			http.Error(rw, "Internal Server Error", http.StatusInternalServerError)
+		}
+	}
+}
+
+// defaultOnNotFound serves not found page of Server or plain text error.
+func defaultOnNotFound(s *Server) func(rw http.ResponseWriter, r *http.Request) {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		if !s.serveErrorPage(rw, r, http.StatusNotFound) {
+			http.NotFound(rw, r)
+		}
+	}
+}
+
+// init indexes and prepares files for serving.
+func (s *Server) init() error {
+	if err := s.hashLayers(); err != nil {
+		return fmt.Errorf("index files: %w", err)
+	}
+
+	if err := s.loadRedirects(); err != nil {
+		return fmt.Errorf("load redirects: %w", err)
+	}
+
+	if err := s.loadHeaders(); err != nil {
+		return fmt.Errorf("load headers: %w", err)
+	}
+
+	s.hashIdentity()
+
 	if s.EncodeOnInit {
-		err := s.encodeFiles()
-		if err != nil {
-			panic(err)
+		if err := s.encodeFiles(); err != nil {
+			return fmt.Errorf("encode files: %w", err)
+		}
+	}
+
+	if s.Fingerprint {
+		s.fingerprintFiles()
+	}
+
+	if s.Fingerprint && s.RewriteReferences {
+		if err := s.rewriteReferences(); err != nil {
+			return fmt.Errorf("rewrite references: %w", err)
 		}
 	}
 
-	return &s
+	if s.CaseInsensitive {
+		if err := s.foldNames(); err != nil {
+			return fmt.Errorf("fold names: %w", err)
+		}
+	}
+
+	return s.mountFiles()
 }
 
 func (s *Server) encodeFiles() error {
@@ -146,14 +329,9 @@ func (s *Server) encodeFiles() error {
 				continue
 			}
 
-			f, err := s.fs.Open(fn)
+			b, err := s.encodeShared(fn, i, enc)
 			if err != nil {
-				return err
-			}
-
-			b, err := enc.Encoder(f)
-			if err != nil {
-				return err
+				return fmt.Errorf("%s %s: %w", enc.ContentEncoding, fn, err)
 			}
 
 			// Skip encoding for non-compressible data.
@@ -165,6 +343,8 @@ func (s *Server) encodeFiles() error {
 				hash:    i.hash + enc.FileExt,
 				size:    len(b),
 				content: b[0:len(b):len(b)],
+				digests: s.reprDigests(b),
+				layer:   i.layer,
 			}
 		}
 	}
@@ -172,60 +352,131 @@ func (s *Server) encodeFiles() error {
 	return nil
 }
 
-func (s *Server) hashDir(p string) error {
-	files, err := s.fs.ReadDir(p)
+func (s *Server) encode(fn string, info fileInfo, enc Encoding) ([]byte, error) {
+	r, err := s.reader(fn, info)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for _, f := range files {
-		fn := path.Join(p, f.Name())
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close() //nolint:errcheck // Read-only file.
+	}
 
-		if f.IsDir() {
-			s.info[path.Clean(fn)] = fileInfo{
-				isDir: true,
+	return enc.Encoder(r)
+}
+
+// hashDir indexes files of a layer, files with logical names owned by upper layers are skipped.
+//
+// Symbolic links to files are followed, symbolic links to directories and special files are skipped.
+func (s *Server) hashDir(layer int, owners map[string]int) error {
+	fsys := s.layers[layer]
+
+	return fs.WalkDir(fsys, ".", func(fn string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("read %s: %w", fn, err)
+		}
+
+		if fn == "." {
+			return nil
+		}
+
+		existing, found := s.info[fn]
+
+		if d.IsDir() {
+			// Upper file shadows lower directory.
+			if found && !existing.isDir {
+				return fs.SkipDir
 			}
 
-			if err = s.hashDir(fn); err != nil {
-				return err
+			s.info[fn] = fileInfo{
+				isDir: true,
 			}
 
-			continue
+			return nil
 		}
 
-		h := fnv.New64()
+		if !d.Type().IsRegular() {
+			fi, err := fs.Stat(fsys, fn)
+			if err != nil {
+				return fmt.Errorf("stat %s: %w", fn, err)
+			}
 
-		f, err := s.fs.Open(fn)
-		if err != nil {
-			return err
+			if !fi.Mode().IsRegular() {
+				return nil
+			}
 		}
 
-		n, err := io.Copy(h, f)
-		if err != nil {
-			return err
+		name := s.encodedBase(fn)
+		if owner, owned := owners[name]; (owned && owner != layer) || found {
+			return nil
 		}
 
-		s.info[path.Clean(fn)] = fileInfo{
-			hash: strconv.FormatUint(h.Sum64(), 36),
-			size: int(n),
-		}
+		owners[name] = layer
+
+		return s.hashFile(layer, fn)
+	})
+}
+
+func (s *Server) hashFile(layer int, fn string) error {
+	h := fnv.New64()
+	d := s.newDigestWriter()
+	w := io.MultiWriter(h, d)
+
+	// Contents are streamed, so that large files of os.DirFS are not loaded into memory.
+	n, err := s.copyFile(w, layer, fn)
+	if err != nil {
+		return fmt.Errorf("hash %s: %w", fn, err)
+	}
+
+	s.info[fn] = fileInfo{
+		hash:    strconv.FormatUint(h.Sum64(), 36),
+		size:    int(n),
+		digests: d.digests(),
+		layer:   layer,
 	}
 
 	return nil
 }
 
+func (s *Server) copyFile(w io.Writer, layer int, fn string) (int64, error) {
+	f, err := s.layers[layer].Open(fn)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close() //nolint:errcheck // Read-only file.
+
+	return io.Copy(w, f)
+}
+
 func (s *Server) reader(fn string, info fileInfo) (io.Reader, error) {
 	if info.content != nil {
 		return bytes.NewReader(info.content), nil
 	}
 
-	return s.fs.Open(fn)
+	return s.layers[info.layer].Open(fn)
 }
 
-func (s *Server) serve(rw http.ResponseWriter, req *http.Request, fn, suf, enc string, info fileInfo,
+// serve writes file contents with response status, status other than http.StatusOK
+// disables conditional and range requests.
+func (s *Server) serve(rw http.ResponseWriter, req *http.Request, status int, fn, suf, enc string, info fileInfo,
 	decompress func(r io.Reader) (io.Reader, error),
 ) {
-	if m := req.Header.Get("If-None-Match"); m == info.hash {
+	if status == http.StatusOK {
+		s.setCustomHeaders(rw.Header(), req.URL.Path)
+	}
+
+	// Caching is only applied to successful response, fingerprinted alias is immutable.
+	cc := ""
+	if status == http.StatusOK && rw.Header().Get("Cache-Control") == "" {
+		if s.aliased(req) {
+			cc = immutableCacheControl
+		} else {
+			cc = s.cacheControl(strings.TrimPrefix(fn, s.fsPrefix))
+		}
+	}
+
+	if m := req.Header.Get("If-None-Match"); m == info.hash && status == http.StatusOK {
+		setCacheControl(rw.Header(), cc)
 		rw.WriteHeader(http.StatusNotModified)
 
 		return
@@ -237,12 +488,15 @@ func (s *Server) serve(rw http.ResponseWriter, req *http.Request, fn, suf, enc s
 	}
 
 	// This is used to enforce application/javascript MIME on Windows (https://github.com/golang/go/issues/32350)
-	if strings.HasSuffix(req.URL.Path, ".js") {
+	if strings.HasSuffix(fn, ".js") {
 		ctype = "application/javascript"
 	}
 
 	rw.Header().Set("Content-Type", ctype)
-	rw.Header().Set("Etag", info.hash)
+
+	if status == http.StatusOK {
+		rw.Header().Set("Etag", info.hash)
+	}
 
 	if enc != "" {
 		rw.Header().Set("Content-Encoding", enc)
@@ -252,39 +506,121 @@ func (s *Server) serve(rw http.ResponseWriter, req *http.Request, fn, suf, enc s
 		rw.Header().Set("Content-Length", strconv.Itoa(info.size))
 	}
 
+	if s.ReprDigest {
+		setDigestHeaders(rw, req, info.digests)
+	}
+
+	if s.LayerHeader != "" {
+		rw.Header().Set(s.LayerHeader, strconv.Itoa(info.layer))
+	}
+
 	if req.Method == http.MethodHead {
+		setCacheControl(rw.Header(), cc)
+
+		if status != http.StatusOK {
+			rw.WriteHeader(status)
+		}
+
 		return
 	}
 
+	s.write(rw, req, status, fn, suf, cc, info, decompress)
+}
+
+// write copies content of a file variant into response, cc is a Cache-Control value
+// that is set once content is available.
+func (s *Server) write(rw http.ResponseWriter, req *http.Request, status int, fn, suf, cc string, info fileInfo,
+	decompress func(r io.Reader) (io.Reader, error),
+) {
 	r, err := s.reader(fn+suf, info)
 	if err != nil {
-		s.OnError(rw, req, err)
+		s.onError(rw, req, status, s.serveError(req, fn+suf, suf, StageOpen, err))
 
 		return
 	}
 
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close() //nolint:errcheck // Read-only file.
+	}
+
 	if decompress != nil {
 		r, err = decompress(r)
 		if err != nil {
-			rw.Header().Del("Etag")
-			s.OnError(rw, req, err)
+			s.onError(rw, req, status, s.serveError(req, fn+suf, suf, StageDecode, err))
 
 			return
 		}
 	}
 
-	if rs, ok := r.(io.ReadSeeker); ok {
-		http.ServeContent(rw, req, fn, time.Time{}, rs)
+	setCacheControl(rw.Header(), cc)
 
-		return
+	if status == http.StatusOK {
+		if rs, ok := r.(io.ReadSeeker); ok {
+			http.ServeContent(rw, req, fn, time.Time{}, rs)
+
+			return
+		}
 	}
 
-	_, err = io.Copy(rw, r)
-	if err != nil {
+	cw := &committedWriter{ResponseWriter: rw}
+	tr := &trackedReader{r: contextReader{ctx: req.Context(), r: r}}
+
+	if status != http.StatusOK {
+		cw.WriteHeader(status)
+	}
+
+	if _, err = io.Copy(cw, tr); err != nil {
+		stage := StageCopy
+		if tr.err != nil && decompress != nil && req.Context().Err() == nil {
+			stage = StageDecode
+		}
+
+		se := s.serveError(req, fn+suf, suf, stage, err)
+		se.HeaderWritten = cw.committed
+
+		s.onError(rw, req, status, se)
+	}
+}
+
+// onError handles serving error, failure of serving an error page is reported with plain text.
+func (s *Server) onError(rw http.ResponseWriter, req *http.Request, status int, err *ServeError) {
+	// Headers of requested file are not applicable to error response, and it must not be cached.
+	if !err.HeaderWritten {
+		deleteFileHeaders(rw.Header())
+	}
+
+	if status == http.StatusOK {
 		s.OnError(rw, req, err)
 
 		return
 	}
+
+	if !err.HeaderWritten {
+		http.Error(rw, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// notFound handles not found file, caching of not found response is controlled by OnNotFound.
+func (s *Server) notFound(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Del("Cache-Control")
+	s.OnNotFound(rw, req)
+}
+
+// fileHeaders are headers of served file representation.
+var fileHeaders = []string{"Cache-Control", "Etag", "Content-Encoding", "Content-Length", "Repr-Digest", "Content-Digest"}
+
+// deleteFileHeaders removes headers of served file, for example before writing an error.
+func deleteFileHeaders(h http.Header) {
+	for _, k := range fileHeaders {
+		h.Del(k)
+	}
+}
+
+// setCacheControl sets non-empty Cache-Control value.
+func setCacheControl(h http.Header, cc string) {
+	if cc != "" {
+		h.Set("Cache-Control", cc)
+	}
 }
 
 func (s *Server) minEnc(accessEncoding string, fn string) (fileInfo, Encoding) {
@@ -317,53 +653,152 @@ func (s *Server) minEnc(accessEncoding string, fn string) (fileInfo, Encoding) {
 // For compatibility with std http.FileServer:
 // if request path ends with /index.html, it is redirected to base directory;
 // if request path points to a directory without trailing "/", it is redirected to a path with trailing "/".
+// This behavior can be changed with IndexNames, TrailingSlash and CleanURLs.
+//
+// Paths that are not in canonical form (for example "//a.js" or "/b/../a.js") are redirected to
+// canonical form, paths that escape root (for example "/../a.js") are rejected with Bad Request.
+// Path without leading "/" (for example after http.StripPrefix) is served as if it had one.
+//
+// If URLPrefix is set, it is removed from request path, requests outside of prefix are not found.
 func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// Path without leading "/", for example after http.StripPrefix, is relative to root.
+	if !strings.HasPrefix(req.URL.Path, "/") {
+		req = withPath(req, "/"+req.URL.Path)
+	}
+
+	if s.urlPrefix != "" {
+		p, ok := s.trimURLPrefix(req.URL.Path)
+		if !ok {
+			s.notFound(rw, req)
+
+			return
+		}
+
+		req = withPath(req, p)
+	}
+
+	s.setSecurityHeaders(rw.Header(), req.URL.Path)
+
+	if s.CORS != nil && s.serveCORS(rw, req) {
+		return
+	}
+
 	if req.Method != http.MethodGet && req.Method != http.MethodHead {
 		rw.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
-		http.Error(rw, "Method Not Allowed\n\nmethod should be GET or HEAD", http.StatusMethodNotAllowed)
 
+		if !s.serveErrorPage(rw, req, http.StatusMethodNotAllowed) {
+			http.Error(rw, "Method Not Allowed\n\nmethod should be GET or HEAD", http.StatusMethodNotAllowed)
+		}
+
+		return
+	}
+
+	if s.redirectCanonical(rw, req) {
 		return
 	}
 
-	if strings.HasSuffix(req.URL.Path, "/index.html") {
-		localRedirect(rw, req, "./")
+	if m, _, ok := s.mountOf(req.URL.Path); ok {
+		// Mounted Server removes its own URLPrefix.
+		m.server.ServeHTTP(rw, withPath(req, s.urlPrefix+req.URL.Path))
 
 		return
 	}
 
+	rewritten := false
+
+	if rule, target, found := s.matchRedirect(req); found {
+		if rule.Status != http.StatusOK {
+			http.Redirect(rw, req, s.redirectTarget(target), rule.Status)
+
+			return
+		}
+
+		req = rewrite(req, target)
+		rewritten = true
+	}
+
+	s.servePath(rw, req, rewritten)
+}
+
+// servePath serves request path, rewritten request is not redirected to canonical index or directory path.
+func (s *Server) servePath(rw http.ResponseWriter, req *http.Request, rewritten bool) {
 	fn := s.fsPrefix + strings.TrimPrefix(req.URL.Path, "/")
-	ae := req.Header.Get("Accept-Encoding")
 
-	if s.info[fn].isDir {
-		localRedirect(rw, req, path.Base(req.URL.Path)+"/")
+	if name, found := s.caseFold(fn); found {
+		if s.CaseRedirect {
+			s.redirectCase(rw, req, name)
+
+			return
+		}
+
+		fn = name
+	}
+
+	if name, found := s.aliases[fn]; found {
+		fn = name
+	}
+
+	if s.denied(req, fn) {
+		s.notFound(rw, req)
+
+		return
+	}
 
+	if !rewritten && s.redirect(rw, req, fn) {
 		return
 	}
 
-	if fn == "" || strings.HasSuffix(fn, "/") {
-		fn += "index.html"
+	fn, isDir := s.resolve(fn)
+
+	if s.denied(req, fn) {
+		s.notFound(rw, req)
+
+		return
 	}
 
+	s.serveResolved(rw, req, fn, isDir)
+}
+
+// serveResolved serves resolved file name, directory listing or SPA fallback.
+func (s *Server) serveResolved(rw http.ResponseWriter, req *http.Request, fn string, isDir bool) {
 	// Always add Accept-Encoding to Vary to prevent intermediate caches corruption.
 	rw.Header().Add("Vary", "Accept-Encoding")
 
-	if ae != "" {
+	if s.serveFile(rw, req, http.StatusOK, fn) {
+		return
+	}
+
+	if isDir && s.serveListing(rw, req, path.Dir(fn)) {
+		return
+	}
+
+	if s.serveSPAFallback(rw, req) {
+		return
+	}
+
+	s.notFound(rw, req)
+}
+
+// serveFile serves the best available variant of a file with response status,
+// it returns false if file is not found.
+func (s *Server) serveFile(rw http.ResponseWriter, req *http.Request, status int, fn string) bool {
+	if ae := req.Header.Get("Accept-Encoding"); ae != "" {
 		minInfo, minEnc := s.minEnc(strings.ToLower(ae), fn)
 
 		if minInfo.hash != "" {
 			// Copy compressed data into response.
-			s.serve(rw, req, fn, minEnc.FileExt, minEnc.ContentEncoding, minInfo, nil)
+			s.serve(rw, req, status, fn, minEnc.FileExt, minEnc.ContentEncoding, minInfo, nil)
 
-			return
+			return true
 		}
 	}
 
 	// Copy uncompressed data into response.
 	uncompressedInfo, uncompressedFound := s.info[fn]
-	if uncompressedFound {
-		s.serve(rw, req, fn, "", "", uncompressedInfo, nil)
+	if uncompressedFound && !uncompressedInfo.isDir {
+		s.serve(rw, req, status, fn, "", "", uncompressedInfo, nil)
 
-		return
+		return true
 	}
 
 	// Decompress compressed data into response.
@@ -375,45 +810,117 @@ func (s *Server) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 		info.hash += "U"
 		info.size = 0
-		s.serve(rw, req, fn, enc.FileExt, "", info, enc.Decoder)
+		if s.ReprDigest {
+			info.digests = s.identity[fn]
+		}
+		s.serve(rw, req, status, fn, enc.FileExt, "", info, enc.Decoder)
 
-		return
+		return true
 	}
 
-	s.OnNotFound(rw, req)
+	return false
 }
 
 // Found returns true if http.Request would be fulfilled by Server.
 //
 // This can be useful for custom handling of requests to non-existent resources.
 func (s *Server) Found(req *http.Request) bool {
+	_, found := s.FoundMount(req)
+
+	return found
+}
+
+// FoundMount returns true if http.Request would be fulfilled by Server, and URL path of the mount
+// that matched request, mount path is empty for file system of Server.
+func (s *Server) FoundMount(req *http.Request) (string, bool) {
+	p, ok := s.trimURLPrefix(req.URL.Path)
+	if !ok {
+		return "", false
+	}
+
+	return s.found(withPath(req, p))
+}
+
+// found checks request with URL path relative to URLPrefix.
+func (s *Server) found(req *http.Request) (string, bool) {
+	c, ok := canonicalPath(req.URL.Path)
+	if !ok {
+		return "", false
+	}
+
+	if m, rel, ok := s.mountOf(c); ok {
+		p, found := m.server.found(withPath(req, rel))
+
+		return m.path + p, found
+	}
+
+	if c != req.URL.Path {
+		req = withPath(req, c)
+	}
+
+	return "", s.foundFile(req)
+}
+
+// foundFile checks request with canonical URL path in file system of Server.
+func (s *Server) foundFile(req *http.Request) bool {
+	if rule, target, found := s.matchRedirect(req); found {
+		if rule.Status != http.StatusOK {
+			return true
+		}
+
+		req = rewrite(req, target)
+	}
+
 	fn := s.fsPrefix + strings.TrimPrefix(req.URL.Path, "/")
 	ae := req.Header.Get("Accept-Encoding")
 
+	if name, found := s.caseFold(fn); found {
+		fn = name
+	}
+
+	if name, found := s.aliases[fn]; found {
+		fn = name
+	}
+
+	if s.denied(req, fn) {
+		return false
+	}
+
 	if s.info[fn].isDir {
 		return true
 	}
 
-	if fn == "" || strings.HasSuffix(fn, "/") {
-		fn += "index.html"
+	fn, isDir := s.resolve(fn)
+
+	if s.denied(req, fn) {
+		return false
+	}
+
+	if s.fileFound(fn, ae) {
+		return true
+	}
+
+	if isDir && s.listingEnabled(req.URL.Path) {
+		return true
 	}
 
+	return s.isClientRoute(req) && s.fileFound(s.fsPrefix+strings.TrimPrefix(s.SPAFallback, "/"), ae)
+}
+
+// fileFound returns true if a variant of file can be served for Accept-Encoding.
+func (s *Server) fileFound(fn, ae string) bool {
 	if ae != "" {
 		minInfo, _ := s.minEnc(strings.ToLower(ae), fn)
 
 		if minInfo.hash != "" {
-			// Copy compressed data into response.
 			return true
 		}
 	}
 
-	// Copy uncompressed data into response.
-	_, uncompressedFound := s.info[fn]
-	if uncompressedFound {
+	if info, found := s.info[fn]; found && !info.isDir {
 		return true
 	}
 
-	// Decompress compressed data into response.
 	for _, enc := range s.Encodings {
 		info, found := s.info[fn+enc.FileExt]
 		if !found || enc.Decoder == nil || info.isDir {
@@ -448,6 +955,8 @@ type fileInfo struct {
 	size    int
 	content []byte
 	isDir   bool
+	digests digests
+	layer   int
 }
 
 // OnError is an option to customize error handling in Server.
@@ -498,6 +1007,111 @@ func EncodeOnInit(server *Server) {
 	server.EncodeOnInit = true
 }
 
+// ReprDigest enables Repr-Digest and Content-Digest response headers.
+func ReprDigest(server *Server) {
+	server.ReprDigest = true
+}
+
+// Fingerprint enables fingerprinted aliases of files.
+func Fingerprint(server *Server) {
+	server.Fingerprint = true
+}
+
+// RewriteReferences enables fingerprinted aliases of files and rewriting of
+// references in HTML and CSS files to use them.
+func RewriteReferences(server *Server) {
+	server.Fingerprint = true
+	server.RewriteReferences = true
+}
+
+// CacheControl is an option to set Cache-Control rules, rules are followed by DefaultCacheRules.
+//
+//	statigz.CacheControl(
+//		statigz.CacheRule{Pattern: "*.woff2", Value: "public, max-age=604800"},
+//		statigz.CacheRule{Regexp: regexp.MustCompile(`^docs/`), Value: "public, max-age=3600"},
+//	)
+func CacheControl(rules ...CacheRule) func(server *Server) {
+	return func(server *Server) {
+		server.CacheRules = append(rules, DefaultCacheRules()...)
+	}
+}
+
+// SPAFallback is an option to serve a document (usually "index.html") for
+// unknown client-side routes of a single-page application.
+func SPAFallback(index string) func(server *Server) {
+	return func(server *Server) {
+		server.SPAFallback = index
+	}
+}
+
+// IndexNames is an option to set names of directory index documents in order of preference.
+func IndexNames(names ...string) func(server *Server) {
+	return func(server *Server) {
+		server.IndexNames = names
+	}
+}
+
+// TrailingSlash is an option to set policy of directory redirects.
+func TrailingSlash(policy TrailingSlashPolicy) func(server *Server) {
+	return func(server *Server) {
+		server.TrailingSlash = policy
+	}
+}
+
+// CleanURLs is an option to serve HTML documents without ".html" extension in URL path.
+func CleanURLs(server *Server) {
+	server.CleanURLs = true
+}
+
+// ErrorPages is an option to serve files as error pages, e.g. {404: "404.html"}.
+func ErrorPages(pages map[int]string) func(server *Server) {
+	return func(server *Server) {
+		server.ErrorPages = pages
+	}
+}
+
+// Redirects is an option to add redirect and rewrite rules.
+func Redirects(rules ...RedirectRule) func(server *Server) {
+	return func(server *Server) {
+		server.Redirects = append(server.Redirects, rules...)
+	}
+}
+
+// Headers is an option to add custom response headers for matching URL paths.
+func Headers(rules ...HeaderRule) func(server *Server) {
+	return func(server *Server) {
+		server.Headers = append(server.Headers, rules...)
+	}
+}
+
+// CaseInsensitive is an option to enable case-insensitive lookup of files,
+// redirect enables redirects to URL path with canonical case.
+func CaseInsensitive(redirect bool) func(server *Server) {
+	return func(server *Server) {
+		server.CaseInsensitive = true
+		server.CaseRedirect = redirect
+	}
+}
+
+// DirListing is an option to enable listing of directories without index,
+// optionally only for directories under provided URL path prefixes.
+func DirListing(prefixes ...string) func(server *Server) {
+	return func(server *Server) {
+		if len(prefixes) == 0 {
+			prefixes = []string{"/"}
+		}
+
+		server.DirListing = prefixes
+	}
+}
+
+// IntegrityHash sets hash algorithm for Server.Integrity: "sha256", "sha384" or "sha512".
+func IntegrityHash(alg string) func(server *Server) {
+	return func(server *Server) {
+		server.IntegrityHash = alg
+	}
+}
+
 // FSPrefix declares file system path prefix that should be ignored.
 func FSPrefix(prefix string) func(server *Server) {
 	return func(server *Server) {
@@ -505,6 +1119,13 @@ func FSPrefix(prefix string) func(server *Server) {
 	}
 }
 
+// URLPrefix declares URL path prefix where Server is mounted, e.g. "/static/".
+func URLPrefix(prefix string) func(server *Server) {
+	return func(server *Server) {
+		server.URLPrefix = prefix
+	}
+}
+
 // localRedirect gives a Moved Permanently response.
 // It does not convert relative paths to absolute paths like Redirect does.
 //
@@ -3,6 +3,7 @@ package statigz_test
 import (
 	"compress/gzip"
 	"embed"
+	"errors"
 	"io"
 	"io/fs"
 	"log"
@@ -160,7 +161,16 @@ func TestServer_ServeHTTP_acceptEncoding(t *testing.T) {
 func TestServer_ServeHTTP_badFile(t *testing.T) {
 	s := statigz.FileServer(v, brotli.AddEncoding,
 		statigz.OnError(func(rw http.ResponseWriter, r *http.Request, err error) {
-			assert.EqualError(t, err, "gzip: invalid header")
+			assert.EqualError(t, err, "decode testdata/bad.png.gz: gzip: invalid header")
+			assert.True(t, errors.Is(err, statigz.ErrDecode))
+			assert.True(t, errors.Is(err, gzip.ErrHeader))
+
+			var se *statigz.ServeError
+			require.True(t, errors.As(err, &se))
+			assert.Equal(t, "/testdata/bad.png", se.Path)
+			assert.Equal(t, "testdata/bad.png.gz", se.Variant)
+			assert.Equal(t, "gzip", se.Encoding)
+			assert.Equal(t, statigz.StageDecode, se.Stage)
 
 			_, err = rw.Write([]byte("failed"))
 			assert.NoError(t, err)
@@ -0,0 +1,102 @@
+package statigz
+
+import (
+	"crypto/sha256"
+	"io"
+	"sync"
+)
+
+// EncodedCache shares encoded contents between servers, for example servers of different hosts
+// in HostRouter, files with identical content are encoded once on Server init.
+//
+// Zero value is ready to use, content is identified by sha-256 digest and encoding.
+type EncodedCache struct {
+	mu    sync.Mutex
+	items map[string][]byte
+}
+
+func (c *EncodedCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, found := c.items[key]
+
+	return b, found
+}
+
+func (c *EncodedCache) set(key string, b []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		c.items = make(map[string][]byte)
+	}
+
+	c.items[key] = b
+}
+
+// Len returns number of cached contents.
+func (c *EncodedCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.items)
+}
+
+// ShareEncoded is an option to share contents encoded with EncodeOnInit between servers.
+func ShareEncoded(cache *EncodedCache) func(server *Server) {
+	return func(server *Server) {
+		server.EncodedCache = cache
+	}
+}
+
+// encodeShared encodes file or takes encoded content of identical file from EncodedCache.
+func (s *Server) encodeShared(fn string, info fileInfo, enc Encoding) ([]byte, error) {
+	if s.EncodedCache == nil {
+		return s.encode(fn, info, enc)
+	}
+
+	sum := info.digests.sha256
+	if sum == nil {
+		var err error
+
+		if sum, err = s.contentSHA256(fn, info); err != nil {
+			return nil, err
+		}
+	}
+
+	key := string(sum) + enc.FileExt
+
+	if b, found := s.EncodedCache.get(key); found {
+		return b, nil
+	}
+
+	b, err := s.encode(fn, info, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	s.EncodedCache.set(key, b)
+
+	return b, nil
+}
+
+// contentSHA256 computes sha-256 digest of file contents to identify it in EncodedCache.
+func (s *Server) contentSHA256(fn string, info fileInfo) ([]byte, error) {
+	r, err := s.reader(fn, info)
+	if err != nil {
+		return nil, err
+	}
+
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close() //nolint:errcheck // Read-only file.
+	}
+
+	h := sha256.New()
+
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
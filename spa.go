@@ -0,0 +1,46 @@
+package statigz
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// isClientRoute checks if request can be served with SPAFallback.
+func (s *Server) isClientRoute(req *http.Request) bool {
+	if s.SPAFallback == "" || path.Ext(req.URL.Path) != "" {
+		return false
+	}
+
+	for _, prefix := range s.SPAExclude {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return false
+		}
+	}
+
+	return strings.Contains(req.Header.Get("Accept"), "text/html")
+}
+
+// serveSPAFallback serves SPAFallback document for client-side routes.
+func (s *Server) serveSPAFallback(rw http.ResponseWriter, req *http.Request) bool {
+	if s.SPAFallback == "" || path.Ext(req.URL.Path) != "" {
+		return false
+	}
+
+	// Response for a path without extension depends on Accept header.
+	rw.Header().Add("Vary", "Accept")
+
+	if !s.isClientRoute(req) {
+		return false
+	}
+
+	rw.Header().Set("Cache-Control", "no-cache")
+
+	if s.serveFile(rw, req, http.StatusOK, s.fsPrefix+strings.TrimPrefix(s.SPAFallback, "/")) {
+		return true
+	}
+
+	rw.Header().Del("Cache-Control")
+
+	return false
+}
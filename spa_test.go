@@ -0,0 +1,72 @@
+package statigz_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+)
+
+func TestSPAFallback(t *testing.T) {
+	s := statigz.FileServer(v, statigz.FSPrefix("testdata"), statigz.SPAFallback("index.html"))
+
+	for _, tc := range []struct {
+		path   string
+		accept string
+		code   int
+		vary   string
+	}{
+		{path: "/users/123", accept: "text/html,application/xhtml+xml", code: http.StatusOK, vary: "Accept-Encoding, Accept"},
+		{path: "/users/123", accept: "application/json", code: http.StatusNotFound, vary: "Accept-Encoding, Accept"},
+		{path: "/app.js", accept: "text/html", code: http.StatusNotFound, vary: "Accept-Encoding"},
+		{path: "/api/users", accept: "text/html", code: http.StatusNotFound, vary: "Accept-Encoding, Accept"},
+		{path: "/swagger.json", accept: "text/html", code: http.StatusOK, vary: "Accept-Encoding"},
+	} {
+		req, err := http.NewRequest(http.MethodGet, tc.path, nil)
+		require.NoError(t, err)
+
+		req.Header.Set("Accept", tc.accept)
+
+		rw := httptest.NewRecorder()
+		s.ServeHTTP(rw, req)
+
+		assert.Equal(t, tc.code, rw.Code, tc.path)
+		assert.Equal(t, tc.code == http.StatusOK, s.Found(req), tc.path)
+		assert.Equal(t, tc.vary, strings.Join(rw.Header().Values("Vary"), ", "), tc.path)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/users/123", nil)
+	require.NoError(t, err)
+
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "no-cache", rw.Header().Get("Cache-Control"))
+	assert.Equal(t, "gzip", rw.Header().Get("Content-Encoding"))
+	assert.Equal(t, "45pls0g4wm91", rw.Header().Get("Etag"))
+
+	r, err := gzip.NewReader(rw.Body)
+	require.NoError(t, err)
+
+	decoded, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello!", string(decoded))
+
+	req.Header.Set("If-None-Match", "45pls0g4wm91")
+
+	rw = httptest.NewRecorder()
+	s.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusNotModified, rw.Code)
+	assert.Equal(t, "no-cache", rw.Header().Get("Cache-Control"))
+}
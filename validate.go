@@ -0,0 +1,107 @@
+package statigz
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// validate checks options of Server.
+func (s *Server) validate() error {
+	if p := strings.Trim(s.FSPrefix, "/"); p != "" && (!fs.ValidPath(p) || p == ".") {
+		return fmt.Errorf("invalid FSPrefix %q", s.FSPrefix)
+	}
+
+	if !validURLPrefix(s.URLPrefix) {
+		return fmt.Errorf("invalid URLPrefix %q", s.URLPrefix)
+	}
+
+	if err := s.validateEncodings(); err != nil {
+		return err
+	}
+
+	if err := s.validateIndexNames(); err != nil {
+		return err
+	}
+
+	mounts := make(map[string]bool, len(s.Mounts))
+
+	for _, m := range s.Mounts {
+		p := "/" + strings.Trim(m.Path, "/")
+
+		if !validURLPrefix(m.Path) {
+			return fmt.Errorf("invalid mount path %q", m.Path)
+		}
+
+		if mounts[p] {
+			return fmt.Errorf("duplicate mount path %q", m.Path)
+		}
+
+		mounts[p] = true
+	}
+
+	return s.validateSecurity()
+}
+
+// validURLPrefix checks that URL path prefix is in canonical form.
+func validURLPrefix(prefix string) bool {
+	p := strings.Trim(prefix, "/")
+	if p == "" {
+		return true
+	}
+
+	c, ok := canonicalPath("/" + p)
+
+	return ok && c == "/"+p && !strings.ContainsAny(p, "?#")
+}
+
+// validateIndexNames checks that index names are file names without directories and do not repeat.
+func (s *Server) validateIndexNames() error {
+	names := make(map[string]bool, len(s.IndexNames))
+
+	for _, name := range s.IndexNames {
+		if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+			return fmt.Errorf("invalid index name %q", name)
+		}
+
+		if names[name] {
+			return fmt.Errorf("duplicate index name %q", name)
+		}
+
+		names[name] = true
+	}
+
+	return nil
+}
+
+// validateEncodings checks that encodings are complete and do not conflict.
+func (s *Server) validateEncodings() error {
+	var (
+		exts  = make(map[string]bool, len(s.Encodings))
+		names = make(map[string]bool, len(s.Encodings))
+	)
+
+	for _, enc := range s.Encodings {
+		if enc.ContentEncoding == "" {
+			return errors.New("missing content encoding")
+		}
+
+		if !strings.HasPrefix(enc.FileExt, ".") || len(enc.FileExt) < 2 {
+			return fmt.Errorf("invalid file extension %q of %s encoding", enc.FileExt, enc.ContentEncoding)
+		}
+
+		if exts[enc.FileExt] {
+			return fmt.Errorf("duplicate file extension %q of %s encoding", enc.FileExt, enc.ContentEncoding)
+		}
+
+		if names[enc.ContentEncoding] {
+			return fmt.Errorf("duplicate content encoding %s", enc.ContentEncoding)
+		}
+
+		exts[enc.FileExt] = true
+		names[enc.ContentEncoding] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package statigz_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vearutop/statigz"
+	"github.com/vearutop/statigz/brotli"
+)
+
+var errBroken = errors.New("broken")
+
+// brokenFS fails to open files with "broken" in name.
+type brokenFS struct {
+	files fstest.MapFS
+}
+
+func (b brokenFS) Open(name string) (fs.File, error) {
+	if strings.Contains(name, "broken") {
+		return nil, errBroken
+	}
+
+	return b.files.Open(name)
+}
+
+func TestNewServer(t *testing.T) {
+	s, err := statigz.NewServer(siteFS, statigz.URLPrefix("/site/"))
+	require.NoError(t, err)
+
+	u, err := s.URL("about.html")
+	require.NoError(t, err)
+	assert.Equal(t, "/site/about.html", u)
+}
+
+func TestNewServer_errors(t *testing.T) {
+	_, err := statigz.NewServer(brokenFS{files: fstest.MapFS{
+		"a.txt":      {Data: []byte("a")},
+		"broken.txt": {Data: []byte("b")},
+	}})
+	assert.True(t, errors.Is(err, errBroken))
+	assert.EqualError(t, err, "index files: hash broken.txt: broken")
+
+	_, err = statigz.NewServer(fstest.MapFS{
+		"app.js": {Data: []byte(strings.Repeat("a", 1000))},
+	}, statigz.EncodeOnInit, func(server *statigz.Server) {
+		server.Encodings[0].Encoder = func(r io.Reader) ([]byte, error) {
+			return nil, errBroken
+		}
+	})
+	assert.True(t, errors.Is(err, errBroken))
+	assert.EqualError(t, err, "encode files: gzip app.js: broken")
+
+	_, err = statigz.NewServer(siteFS, statigz.Mount("/docs", brokenFS{files: fstest.MapFS{
+		"broken.html": {Data: []byte("b")},
+	}}))
+	assert.True(t, errors.Is(err, errBroken))
+	assert.EqualError(t, err, "mount /docs: index files: hash broken.html: broken")
+}
+
+func TestNewServer_validate(t *testing.T) {
+	for expected, options := range map[string][]func(server *statigz.Server){
+		`invalid FSPrefix "../static"`:                  {statigz.FSPrefix("../static")},
+		`invalid FSPrefix "./"`:                         {statigz.FSPrefix("./")},
+		`invalid URLPrefix "/a/../b"`:                   {statigz.URLPrefix("/a/../b")},
+		`invalid URLPrefix "/a//b"`:                     {statigz.URLPrefix("/a//b")},
+		`invalid mount path "/docs/./"`:                 {statigz.Mount("/docs/./", siteFS)},
+		`duplicate mount path "/docs/"`:                 {statigz.Mount("/docs", siteFS), statigz.Mount("/docs/", siteFS)},
+		`duplicate file extension ".br" of br encoding`: {brotli.AddEncoding, brotli.AddEncoding},
+		`invalid index name ""`:                         {statigz.IndexNames("")},
+		`invalid index name "docs/index.html"`:          {statigz.IndexNames("index.html", "docs/index.html")},
+		`invalid index name ".."`:                       {statigz.IndexNames("..")},
+		`duplicate index name "index.html"`:             {statigz.IndexNames("index.html", "index.htm", "index.html")},
+		`duplicate content encoding gzip`: {func(server *statigz.Server) {
+			enc := statigz.GzipEncoding()
+			enc.FileExt = ".gzip"
+			server.Encodings = append(server.Encodings, enc)
+		}},
+		`invalid file extension "gz" of gzip encoding`: {func(server *statigz.Server) {
+			server.Encodings[0].FileExt = "gz"
+		}},
+		`security *.js: path pattern must start with /: "*.js"`: {statigz.SecurityHeaders(statigz.StrictSecurity,
+			statigz.SecurityRule{Path: "*.js"})},
+	} {
+		_, err := statigz.NewServer(siteFS, options...)
+		assert.EqualError(t, err, "validate options: "+expected)
+	}
+}
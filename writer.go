@@ -0,0 +1,38 @@
+package statigz
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// committedWriter tracks whether response status and headers were written.
+type committedWriter struct {
+	http.ResponseWriter
+	committed bool
+}
+
+func (w *committedWriter) WriteHeader(status int) {
+	w.committed = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *committedWriter) Write(p []byte) (int, error) {
+	w.committed = true
+
+	return w.ResponseWriter.Write(p)
+}
+
+// contextReader stops reading when context is done, for example when client disconnects.
+type contextReader struct {
+	ctx context.Context //nolint:containedctx // Reader is bound to request.
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return c.r.Read(p)
+}